@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// AccreditationQuery: query parameter GET /reports/accreditation.
+// TS = tahun awal tahun akademik terakhir yang dilaporkan (TS 2025 = 2025/2026).
+type AccreditationQuery struct {
+	TS           int    `query:"ts" json:"ts" validate:"omitempty,min=2000,max=2100"`
+	ProgramStudy string `query:"program_study" json:"program_study,omitempty" validate:"max=100"`
+	DateField    string `query:"date_field" json:"date_field,omitempty" validate:"omitempty,oneof=event verified"`
+}
+
+// AccreditationReport: indikator akreditasi (BAN-PT / LAM) dan IKU
+// untuk jendela TS-2, TS-1 dan TS
+type AccreditationReport struct {
+	TS           int                   `json:"ts"`
+	ProgramStudy string                `json:"program_study"`
+	DateField    string                `json:"date_field"`
+	Windows      []AccreditationWindow `json:"windows"`
+	Total        AccreditationLevels   `json:"total"`
+}
+
+type AccreditationWindow struct {
+	Label        string              `json:"label"`         // TS-2, TS-1, TS
+	AcademicYear string              `json:"academic_year"` // contoh: 2023/2024
+	Start        time.Time           `json:"start"`
+	End          time.Time           `json:"end"` // eksklusif
+	Levels       AccreditationLevels `json:"levels"`
+	IKU          IKUIndicator        `json:"iku"`
+}
+
+// AccreditationLevels: jumlah prestasi per tingkat (kolom tabel LKPS)
+type AccreditationLevels struct {
+	LocalRegional int `json:"local_regional"` // lokal / wilayah
+	National      int `json:"national"`
+	International int `json:"international"`
+	Unknown       int `json:"unknown"` // tingkat belum diisi, tidak masuk tabel LKPS
+	Total         int `json:"total"`
+}
+
+// IKUIndicator: persentase mahasiswa aktif dengan prestasi
+// tingkat nasional / internasional
+type IKUIndicator struct {
+	ActiveStudents    int     `json:"active_students"`
+	AchievingStudents int     `json:"achieving_students"`
+	Percentage        float64 `json:"percentage"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Role anggota pada achievement beregu
+const (
+	MemberRoleLeader = "ketua"
+	MemberRoleMember = "anggota"
+)
+
+// Aturan pembagian poin achievement beregu
+const (
+	PointRuleDuplicate = "duplicate" // setiap anggota mendapat poin penuh
+	PointRuleSplit     = "split"     // poin dibagi rata ke semua anggota
+)
+
+type AchievementMember struct {
+	ID          string     `json:"id" db:"id"`
+	MongoID     string     `json:"mongo_achievement_id" db:"mongo_achievement_id"`
+	StudentID   string     `json:"student_id" db:"student_id"`
+	Role        string     `json:"role" db:"role"`
+	ConfirmedAt *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ===== REQUEST BODY (ANGGOTA TIM) =====
+type AchievementMemberRequest struct {
+	StudentID string `json:"studentId" validate:"required,uuid"`
+	Role      string `json:"role" validate:"omitempty,oneof=ketua anggota"` // kosong = anggota
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,33 +18,143 @@ type Achievement struct {
 	Details         AchievementDetails  `bson:"details" json:"details"`
 	Tags            []string            `bson:"tags" json:"tags"`
 	Points          int                 `bson:"points" json:"points"`
+	PointRuleVersion int                `bson:"pointRuleVersion" json:"pointRuleVersion"` // versi rule set yang menghitung Points
 	Attachments []AchievementAttachment `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	PointRule       string              `bson:"pointRule,omitempty" json:"pointRule,omitempty"` // khusus achievement beregu
+	DuplicateFlags  []DuplicateFlag     `bson:"duplicateFlags,omitempty" json:"duplicateFlags,omitempty"`
 	CreatedAt       time.Time           `bson:"createdAt" json:"createdAt"`
 	UpdatedAt       time.Time           `bson:"updatedAt" json:"updatedAt"`
 }
 
 type AchievementDetails struct {
-	CompetitionName  string `bson:"competitionName" json:"competitionName"`
-	CompetitionLevel string `bson:"competitionLevel" json:"competitionLevel"`
-	Rank *float64 `bson:"rank,omitempty" json:"rank,omitempty"`
-	MedalType        string `bson:"medalType" json:"medalType"`
-	EventDate        string `bson:"eventDate" json:"eventDate"`
-	Location         string `bson:"location" json:"location"`
-	Organizer        string `bson:"organizer" json:"organizer"`
+	CompetitionName  string `bson:"competitionName" json:"competitionName" validate:"max=255"`
+	CompetitionLevel string `bson:"competitionLevel" json:"competitionLevel" validate:"omitempty,oneof=local regional national international"`
+	Rank *float64 `bson:"rank,omitempty" json:"rank,omitempty" validate:"omitempty,gte=1"`
+	MedalType        string `bson:"medalType" json:"medalType" validate:"max=50"`
+	EventDate        string `bson:"eventDate" json:"eventDate" validate:"omitempty,datetime=2006-01-02"`
+	Location         string `bson:"location" json:"location" validate:"max=255"`
+	Organizer        string `bson:"organizer" json:"organizer" validate:"max=255"`
+
+	// ===== ORGANIZATION =====
+	OrganizationName string `bson:"organizationName,omitempty" json:"organizationName,omitempty"`
+	Position         string `bson:"position,omitempty" json:"position,omitempty"` // jabatan: ketua, sekretaris, ...
+	PeriodStart      string `bson:"periodStart,omitempty" json:"periodStart,omitempty" validate:"omitempty,datetime=2006-01-02"` // YYYY-MM-DD
+	PeriodEnd        string `bson:"periodEnd,omitempty" json:"periodEnd,omitempty" validate:"omitempty,datetime=2006-01-02"`     // YYYY-MM-DD
+
+	// ===== SCHOLARSHIP =====
+	ScholarshipName string   `bson:"scholarshipName,omitempty" json:"scholarshipName,omitempty"`
+	Provider        string   `bson:"provider,omitempty" json:"provider,omitempty"`
+	Amount          *float64 `bson:"amount,omitempty" json:"amount,omitempty" validate:"omitempty,gte=0"` // rupiah
+
+	// ===== COMMUNITY SERVICE =====
+	ActivityName string   `bson:"activityName,omitempty" json:"activityName,omitempty"`
+	Hours        *float64 `bson:"hours,omitempty" json:"hours,omitempty" validate:"omitempty,gt=0"`
+
+	// ===== IPR (HKI / PATEN) =====
+	IPRType            string `bson:"iprType,omitempty" json:"iprType,omitempty" validate:"omitempty,oneof=patent simple_patent copyright trademark industrial_design"` // patent, simple_patent, copyright, trademark, industrial_design
+	RegistrationNumber string `bson:"registrationNumber,omitempty" json:"registrationNumber,omitempty"`
+	RegistrationDate   string `bson:"registrationDate,omitempty" json:"registrationDate,omitempty" validate:"omitempty,datetime=2006-01-02"`
+
+	// ===== PUBLICATION =====
+	PublicationType string `bson:"publicationType,omitempty" json:"publicationType,omitempty" validate:"omitempty,oneof=journal conference book"` // journal, conference, book
+	Venue           string `bson:"venue,omitempty" json:"venue,omitempty"`                     // nama jurnal / konferensi
+	Indexing        string `bson:"indexing,omitempty" json:"indexing,omitempty"`               // scopus, wos, sinta1..sinta6, ...
+	DOI             string `bson:"doi,omitempty" json:"doi,omitempty"`
+
+	// ===== CERTIFICATION =====
+	CertificationName string `bson:"certificationName,omitempty" json:"certificationName,omitempty"`
+	Issuer            string `bson:"issuer,omitempty" json:"issuer,omitempty"`
+	CertificateNumber string `bson:"certificateNumber,omitempty" json:"certificateNumber,omitempty"`
+	ValidUntil        string `bson:"validUntil,omitempty" json:"validUntil,omitempty" validate:"omitempty,datetime=2006-01-02"`
 }
 // ===== REQUEST BODY (CREATE ACHIEVEMENT) =====
 type AchievementCreateRequest struct {
-	StudentID       string             `json:"studentId,omitempty"` 
-	AchievementType string             `json:"achievementType"`
-	Title           string             `json:"title"`
-	Description     string             `json:"description"`
+	StudentID       string             `json:"studentId,omitempty" validate:"omitempty,uuid"`
+	AchievementType string             `json:"achievementType" validate:"required,achievement_type"`
+	Title           string             `json:"title" validate:"notblank,max=255"`
+	Description     string             `json:"description" validate:"max=5000"`
 	Details         AchievementDetails `json:"details"` // ✅ FIX
-	Tags            []string           `json:"tags"`
+	Tags            []string           `json:"tags" validate:"max=20,dive,notblank,max=50"`
+	Members         []AchievementMemberRequest `json:"members,omitempty" validate:"omitempty,dive"` // kosong = achievement individu
+	PointRule       string                     `json:"pointRule,omitempty" validate:"omitempty,oneof=duplicate split"`
 }
 
 type AchievementAttachment struct {
+	ID         string    `bson:"id,omitempty" json:"id"`
 	FileName   string    `bson:"fileName" json:"fileName"`
 	FileURL    string    `bson:"fileUrl" json:"fileUrl"`
 	FileType   string    `bson:"fileType" json:"fileType"`
+	Size       int64     `bson:"size,omitempty" json:"size,omitempty"` // byte
+	SHA256     string    `bson:"sha256,omitempty" json:"sha256,omitempty"` // hex, dihitung server saat upload
+	StorageKey string    `bson:"storageKey,omitempty" json:"-"` // key di storage backend
+	ThumbnailKey string  `bson:"thumbnailKey,omitempty" json:"-"` // hanya untuk gambar
 	UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"`
+
+	// hasil scan malware; kosong = attachment lama yang belum pernah discan
+	ScanStatus    string     `bson:"scanStatus,omitempty" json:"scanStatus,omitempty"`
+	ScanSignature string     `bson:"scanSignature,omitempty" json:"scanSignature,omitempty"`
+	ScannedAt     *time.Time `bson:"scannedAt,omitempty" json:"scannedAt,omitempty"`
+}
+
+// Status scan malware attachment
+const (
+	ScanPending  = "pending"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+)
+
+// ScanState: attachment lama tanpa status dianggap pending
+func (a AchievementAttachment) ScanState() string {
+	if a.ScanStatus == "" {
+		return ScanPending
+	}
+	return a.ScanStatus
+}
+
+// AttachmentID mengembalikan ID attachment. Attachment lama belum punya
+// id, dipakai hash dari key storage supaya tetap stabil.
+func (a AchievementAttachment) AttachmentID() string {
+	if a.ID != "" {
+		return a.ID
+	}
+	sum := sha1.Sum([]byte(a.Key()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Key mengembalikan key storage attachment. Attachment lama belum punya
+// storageKey, key-nya diturunkan dari fileUrl ("/uploads/<key>").
+func (a AchievementAttachment) Key() string {
+	if a.StorageKey != "" {
+		return a.StorageKey
+	}
+	return strings.TrimPrefix(a.FileURL, "/uploads/")
+}
+
+// EvidenceReuse: file attachment yang hash-nya sama dengan attachment
+// di achievement lain (ditampilkan ke verifikator)
+type EvidenceReuse struct {
+	AttachmentID string               `json:"attachmentId"`
+	FileName     string               `json:"fileName"`
+	SHA256       string               `json:"sha256"`
+	Matches      []EvidenceReuseMatch `json:"matches"`
+}
+
+type EvidenceReuseMatch struct {
+	AchievementID string `json:"achievementId"`
+	StudentID     string `json:"studentId"`
+	Title         string `json:"title"`
+	AttachmentID  string `json:"attachmentId"`
+	FileName      string `json:"fileName"`
+	SameStudent   bool   `json:"sameStudent"` // false = file milik student lain
+}
+
+// DuplicateFlag menandai achievement lain yang kemungkinan besar sama
+// (diisi saat create & submit, ditampilkan ke verifikator)
+type DuplicateFlag struct {
+	AchievementID string   `bson:"achievementId" json:"achievementId"`
+	StudentID     string   `bson:"studentId" json:"studentId"`
+	Title         string   `bson:"title" json:"title"`
+	Score         float64  `bson:"score" json:"score"`
+	MatchedFields []string `bson:"matchedFields" json:"matchedFields"`
+	SameRankClaim bool     `bson:"sameRankClaim" json:"sameRankClaim"` // student lain mengklaim peringkat yang sama
 }
@@ -3,15 +3,18 @@ package models
 import "time"
 
 type AchievementReference struct {
-    ID            string     `db:"id"`
-    StudentID     string     `db:"student_id"`
-    MongoID       string     `db:"mongo_achievement_id"`
-    Status        string     `db:"status"` // draft, submitted, verified, rejected, deleted    
-    SubmittedAt   *time.Time `db:"submitted_at"`
-    VerifiedAt    *time.Time `db:"verified_at"`
-    VerifiedBy    *string    `db:"verified_by"`
-    RejectionNote *string    `db:"rejection_note"`
-    CreatedAt     time.Time  `db:"created_at"`
-    UpdatedAt     time.Time  `db:"updated_at"`
+    ID               string     `db:"id"`
+    StudentID        string     `db:"student_id"`
+    MongoID          string     `db:"mongo_achievement_id"`
+    Status           string     `db:"status"` // draft, submitted, verified, rejected, revoked, deleted
+    SubmittedAt      *time.Time `db:"submitted_at"`
+    VerifiedAt       *time.Time `db:"verified_at"`
+    VerifiedBy       *string    `db:"verified_by"`
+    RejectionNote    *string    `db:"rejection_note"`
+    RevokedAt        *time.Time `db:"revoked_at"`
+    RevokedBy        *string    `db:"revoked_by"`
+    RevocationReason *string    `db:"revocation_reason"`
+    CreatedAt        time.Time  `db:"created_at"`
+    UpdatedAt        time.Time  `db:"updated_at"`
 
 }
\ No newline at end of file
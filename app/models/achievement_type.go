@@ -0,0 +1,216 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Jenis achievement yang didukung
+const (
+	TypeCompetition      = "competition"
+	TypePublication      = "publication"
+	TypeOrganization     = "organization"
+	TypeCertification    = "certification"
+	TypeScholarship      = "scholarship"
+	TypeCommunityService = "community_service"
+	TypeIPR              = "ipr"
+)
+
+// AchievementTypeSpec mendeskripsikan field details yang dipakai satu jenis
+// achievement (nama field = nama JSON di AchievementDetails)
+type AchievementTypeSpec struct {
+	Type     string   `json:"type"`
+	Label    string   `json:"label"`
+	LabelEN  string   `json:"labelEn"` // dipakai dokumen bilingual (SKPI)
+	Required []string `json:"required"`
+	Optional []string `json:"optional"`
+}
+
+// field umum yang boleh diisi semua jenis achievement
+var commonDetailFields = []string{"competitionLevel", "eventDate", "location", "organizer"}
+
+var AchievementTypes = []AchievementTypeSpec{
+	{
+		Type:     TypeCompetition,
+		Label:    "Kompetisi / Lomba",
+		LabelEN:  "Competition",
+		Required: []string{"competitionName", "competitionLevel", "eventDate"},
+		Optional: []string{"rank", "medalType"},
+	},
+	{
+		Type:     TypePublication,
+		Label:    "Publikasi Ilmiah",
+		LabelEN:  "Scientific Publication",
+		Required: []string{"publicationType", "venue", "eventDate"},
+		Optional: []string{"indexing", "doi"},
+	},
+	{
+		Type:     TypeOrganization,
+		Label:    "Organisasi",
+		LabelEN:  "Organizational Experience",
+		Required: []string{"organizationName", "position", "periodStart"},
+		Optional: []string{"periodEnd"},
+	},
+	{
+		Type:     TypeCertification,
+		Label:    "Sertifikasi",
+		LabelEN:  "Professional Certification",
+		Required: []string{"certificationName", "issuer", "eventDate"},
+		Optional: []string{"certificateNumber", "validUntil"},
+	},
+	{
+		Type:     TypeScholarship,
+		Label:    "Beasiswa",
+		LabelEN:  "Scholarship",
+		Required: []string{"scholarshipName", "provider", "periodStart"},
+		Optional: []string{"amount", "periodEnd"},
+	},
+	{
+		Type:     TypeCommunityService,
+		Label:    "Pengabdian Masyarakat",
+		LabelEN:  "Community Service",
+		Required: []string{"activityName", "hours", "eventDate"},
+		Optional: []string{"periodEnd"},
+	},
+	{
+		Type:     TypeIPR,
+		Label:    "HKI / Paten",
+		LabelEN:  "Intellectual Property Rights",
+		Required: []string{"iprType", "registrationNumber", "registrationDate"},
+		Optional: []string{},
+	},
+}
+
+// GetAchievementTypeSpec mengembalikan nil jika jenis tidak dikenal
+func GetAchievementTypeSpec(achievementType string) *AchievementTypeSpec {
+	for i := range AchievementTypes {
+		if AchievementTypes[i].Type == achievementType {
+			return &AchievementTypes[i]
+		}
+	}
+	return nil
+}
+
+// AllowedFields = required + optional + field umum
+func (spec *AchievementTypeSpec) AllowedFields() map[string]bool {
+	allowed := make(map[string]bool)
+	for _, group := range [][]string{spec.Required, spec.Optional, commonDetailFields} {
+		for _, f := range group {
+			allowed[f] = true
+		}
+	}
+	return allowed
+}
+
+// MissingFields mengembalikan field wajib yang masih kosong
+func (spec *AchievementTypeSpec) MissingFields(d AchievementDetails) []string {
+	var missing []string
+	v := reflect.ValueOf(d)
+	t := v.Type()
+
+	required := make(map[string]bool)
+	for _, f := range spec.Required {
+		required[f] = true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := detailFieldName(t.Field(i))
+		if required[name] && v.Field(i).IsZero() {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+// Normalize mengosongkan field yang bukan milik jenis achievement ini,
+// supaya data yang tersimpan konsisten per jenis.
+func (spec *AchievementTypeSpec) Normalize(d AchievementDetails) AchievementDetails {
+	allowed := spec.AllowedFields()
+
+	v := reflect.ValueOf(&d).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !allowed[detailFieldName(t.Field(i))] {
+			v.Field(i).Set(reflect.Zero(t.Field(i).Type))
+		}
+	}
+
+	return d
+}
+
+func detailFieldName(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("json"), ",")[0]
+}
+
+// ReportLevel: tingkat achievement untuk laporan. Publikasi memakai
+// indeksasi jika tingkat tidak diisi.
+func ReportLevel(achievementType string, d AchievementDetails) string {
+	if d.CompetitionLevel != "" {
+		return d.CompetitionLevel
+	}
+	if achievementType == TypePublication && d.Indexing != "" {
+		return d.Indexing
+	}
+	return "unknown"
+}
+
+// EventDate: tanggal kegiatan sesuai jenis achievement (eventDate,
+// periodStart, lalu registrationDate), "" jika kosong
+func EventDate(d AchievementDetails) string {
+	for _, date := range []string{d.EventDate, d.PeriodStart, d.RegistrationDate} {
+		if date != "" {
+			return date
+		}
+	}
+	return ""
+}
+
+// DetailSummary: ringkasan satu baris details sesuai jenis achievement
+func DetailSummary(achievementType string, d AchievementDetails) string {
+	period := func(start, end string) string {
+		if end == "" {
+			return start
+		}
+		return start + " s/d " + end
+	}
+
+	switch achievementType {
+	case TypeCompetition:
+		s := d.CompetitionName
+		if d.Rank != nil {
+			s += fmt.Sprintf(" - juara %g", *d.Rank)
+		}
+		if d.MedalType != "" {
+			s += " (" + d.MedalType + ")"
+		}
+		return s
+	case TypePublication:
+		s := d.PublicationType + ": " + d.Venue
+		if d.Indexing != "" {
+			s += " [" + d.Indexing + "]"
+		}
+		return s
+	case TypeOrganization:
+		return d.Position + ", " + d.OrganizationName + " (" + period(d.PeriodStart, d.PeriodEnd) + ")"
+	case TypeCertification:
+		return d.CertificationName + " - " + d.Issuer
+	case TypeScholarship:
+		s := d.ScholarshipName + " - " + d.Provider
+		if d.Amount != nil {
+			s += fmt.Sprintf(" (Rp %.0f)", *d.Amount)
+		}
+		return s
+	case TypeCommunityService:
+		s := d.ActivityName
+		if d.Hours != nil {
+			s += fmt.Sprintf(" (%g jam)", *d.Hours)
+		}
+		return s
+	case TypeIPR:
+		return d.IPRType + " No. " + d.RegistrationNumber
+	default:
+		return d.CompetitionName
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Aksi pada attachment yang dicatat di riwayat achievement
+const (
+	AttachmentUploaded  = "uploaded"
+	AttachmentDeleted   = "deleted"
+	AttachmentReplaced  = "replaced"
+	AttachmentReordered = "reordered"
+)
+
+type AttachmentEvent struct {
+	ID           string    `json:"id" db:"id"`
+	MongoID      string    `json:"mongo_achievement_id" db:"mongo_achievement_id"`
+	AttachmentID string    `json:"attachment_id" db:"attachment_id"` // kosong untuk reordered
+	Action       string    `json:"action" db:"action"`
+	FileName     string    `json:"file_name" db:"file_name"`
+	ActorID      *string   `json:"actor_id" db:"actor_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ===== REQUEST BODY (URUTAN ATTACHMENT) =====
+type AttachmentOrderRequest struct {
+	Order []string `json:"order" validate:"required,min=1,dive,required"` // semua attachment ID, urutan baru
+}
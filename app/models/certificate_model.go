@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AchievementCertificate: satu sertifikat per achievement verified.
+// Code dicetak di sertifikat dan dipakai di URL verifikasi publik.
+type AchievementCertificate struct {
+	ID       string    `json:"id" db:"id"`
+	MongoID  string    `json:"mongo_achievement_id" db:"mongo_achievement_id"`
+	Code     string    `json:"code" db:"code"`
+	IssuedBy *string   `json:"issued_by" db:"issued_by"`
+	IssuedAt time.Time `json:"issued_at" db:"issued_at"`
+}
+
+// CertificateVerification adalah response publik /verify/:code.
+// Tidak memuat data pribadi selain nama dan NIM yang disamarkan.
+type CertificateVerification struct {
+	Code             string     `json:"code"`
+	Valid            bool       `json:"valid"` // true hanya jika achievement masih verified
+	Status           string     `json:"status"`
+	StudentName      string     `json:"student_name"`
+	StudentNumber    string     `json:"student_number"`
+	ProgramStudy     string     `json:"program_study"`
+	Title            string     `json:"title"`
+	AchievementType  string     `json:"achievement_type"`
+	Level            string     `json:"level"`
+	VerifiedAt       *time.Time `json:"verified_at"`
+	VerifiedBy       string     `json:"verified_by"`
+	IssuedAt         time.Time  `json:"issued_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevocationReason *string    `json:"revocation_reason,omitempty"`
+}
@@ -9,3 +9,59 @@ type Lecturer struct {
 	Department string    `json:"department" db:"department"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
+
+// ===== DASHBOARD DOSEN WALI =====
+
+type AdvisorDashboard struct {
+	Lecturer         Lecturer              `json:"lecturer"`
+	Semester         Semester              `json:"semester"`
+	Summary          AdvisorSummary        `json:"summary"`
+	Advisees         []AdviseeSummary      `json:"advisees"`
+	PendingQueue     []PendingVerification `json:"pending_queue"`     // submitted, paling lama di atas
+	InactiveAdvisees []AdviseeSummary      `json:"inactive_advisees"` // belum ada achievement semester ini
+}
+
+// Semester: ganjil = Agustus–Januari, genap = Februari–Juli
+type Semester struct {
+	Name  string    `json:"name"` // contoh: "Ganjil 2025/2026"
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"` // eksklusif
+}
+
+type AdvisorSummary struct {
+	TotalAdvisees       int `json:"total_advisees"`
+	PendingCount        int `json:"pending_count"`
+	OldestPendingDays   int `json:"oldest_pending_days"`
+	InactiveCount       int `json:"inactive_count"`
+	TotalVerifiedPoints int `json:"total_verified_points"`
+}
+
+type AdviseeSummary struct {
+	ID                   string         `json:"id"`
+	StudentID            string         `json:"student_id"`
+	Name                 string         `json:"name"`
+	ProgramStudy         string         `json:"program_study"`
+	AcademicYear         string         `json:"academic_year"`
+	StatusCounts         map[string]int `json:"status_counts"` // draft, submitted, verified, rejected, revoked
+	TotalAchievements    int            `json:"total_achievements"`
+	SemesterAchievements int            `json:"semester_achievements"`
+	VerifiedPoints       int            `json:"verified_points"`
+}
+
+type PendingVerification struct {
+	AchievementID   string     `json:"achievement_id"`
+	StudentID       string     `json:"student_id"`
+	StudentName     string     `json:"student_name"`
+	Title           string     `json:"title"`
+	AchievementType string     `json:"achievement_type"`
+	SubmittedAt     *time.Time `json:"submitted_at"`
+	AgeDays         int        `json:"age_days"`
+}
+
+// StudentStatusCount: jumlah achievement satu mahasiswa per status
+type StudentStatusCount struct {
+	StudentID  string
+	Status     string
+	Total      int
+	SinceCount int // dibuat sejak awal periode (semester)
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Scope job rekalkulasi poin
+const (
+	RecalcScopeAll    = "all"
+	RecalcScopeType   = "type"   // ScopeValue = achievementType
+	RecalcScopeStatus = "status" // ScopeValue = status reference (verified, draft, ...)
+)
+
+// Mode job rekalkulasi poin
+const (
+	RecalcModePreview = "preview" // hanya hitung, tidak mengubah data
+	RecalcModeApply   = "apply"
+)
+
+// Status job rekalkulasi poin
+const (
+	RecalcStatusPending   = "pending"
+	RecalcStatusRunning   = "running"
+	RecalcStatusCompleted = "completed"
+	RecalcStatusFailed    = "failed"
+)
+
+type PointRecalcJob struct {
+	ID             string     `json:"id" db:"id"`
+	Scope          string     `json:"scope" db:"scope"`
+	ScopeValue     string     `json:"scope_value" db:"scope_value"`
+	Mode           string     `json:"mode" db:"mode"`
+	Status         string     `json:"status" db:"status"`
+	RuleSetVersion int        `json:"rule_set_version" db:"rule_set_version"`
+	Total          int        `json:"total" db:"total"`
+	Changed        int        `json:"changed" db:"changed"`
+	Error          *string    `json:"error" db:"error"`
+	CreatedBy      *string    `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time `json:"started_at" db:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// PointRecalcItem adalah audit per achievement: poin lama vs poin baru
+type PointRecalcItem struct {
+	ID             string `json:"id" db:"id"`
+	JobID          string `json:"job_id" db:"job_id"`
+	MongoID        string `json:"mongo_achievement_id" db:"mongo_achievement_id"`
+	StudentID      string `json:"student_id" db:"student_id"`
+	Status         string `json:"status" db:"status"`
+	OldPoints      int    `json:"old_points" db:"old_points"`
+	NewPoints      int    `json:"new_points" db:"new_points"`
+	OldRuleVersion int    `json:"old_rule_version" db:"old_rule_version"`
+	NewRuleVersion int    `json:"new_rule_version" db:"new_rule_version"`
+	Applied        bool   `json:"applied" db:"applied"`
+}
+
+// PointRecalcStudentTotal: total poin verified student sebelum & sesudah
+type PointRecalcStudentTotal struct {
+	JobID     string `json:"-" db:"job_id"`
+	StudentID string `json:"student_id" db:"student_id"`
+	OldTotal  int    `json:"old_total" db:"old_total"`
+	NewTotal  int    `json:"new_total" db:"new_total"`
+	Delta     int    `json:"delta" db:"-"`
+}
+
+// ===== REQUEST BODY (START JOB) =====
+type PointRecalcRequest struct {
+	Scope      string `json:"scope" validate:"omitempty,oneof=all type status"`
+	ScopeValue string `json:"scope_value"` // achievementType atau status (dicek di validation)
+	Preview    bool   `json:"preview"`
+}
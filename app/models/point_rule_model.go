@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// PointRuleSet adalah satu versi pedoman poin. Rule set tidak diubah
+// setelah dibuat; perubahan pedoman = buat versi baru lalu aktifkan.
+type PointRuleSet struct {
+	ID          string      `json:"id" db:"id"`
+	Version     int         `json:"version" db:"version"`
+	Name        string      `json:"name" db:"name"`
+	Description string      `json:"description" db:"description"`
+	IsActive    bool        `json:"is_active" db:"is_active"`
+	CreatedBy   *string     `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	ActivatedAt *time.Time  `json:"activated_at" db:"activated_at"`
+	Rules       []PointRule `json:"rules,omitempty"`
+}
+
+// PointRule: kondisi yang nil berarti "apa saja".
+// Rule dievaluasi urut priority (kecil dulu), rule pertama yang cocok dipakai.
+type PointRule struct {
+	ID               string  `json:"id" db:"id"`
+	RuleSetID        string  `json:"rule_set_id" db:"rule_set_id"`
+	Priority         int     `json:"priority" db:"priority" validate:"gte=0"`
+	AchievementType  *string `json:"achievement_type" db:"achievement_type" validate:"omitempty,achievement_type"`
+	CompetitionLevel *string `json:"competition_level" db:"competition_level" validate:"omitempty,oneof=local regional national international"`
+	Rank             *int    `json:"rank" db:"rank" validate:"omitempty,gte=1"`
+	MedalType        *string `json:"medal_type" db:"medal_type" validate:"omitempty,max=50"`
+	Tag              *string `json:"tag" db:"tag" validate:"omitempty,max=50"`
+	Points           int     `json:"points" db:"points" validate:"gte=0"`
+	Description      string  `json:"description" db:"description"`
+}
+
+// ===== REQUEST BODY (CREATE RULE SET) =====
+type PointRuleSetRequest struct {
+	Name        string      `json:"name" validate:"notblank,max=100"`
+	Description string      `json:"description"`
+	Rules       []PointRule `json:"rules" validate:"required,min=1,dive"`
+	Activate    bool        `json:"activate"` // langsung aktifkan setelah dibuat
+}
+
+// ===== REQUEST BODY (DRY RUN) =====
+// isi salah satu: achievementId (achievement yang sudah ada) atau achievement
+type PointDryRunRequest struct {
+	AchievementID string                    `json:"achievementId,omitempty" validate:"required_without=Achievement,omitempty,mongodb"`
+	Achievement   *AchievementCreateRequest `json:"achievement,omitempty"`
+	RuleSetID     string                    `json:"ruleSetId,omitempty" validate:"omitempty,uuid"` // kosong = rule set aktif
+}
+
+// PointEvaluation adalah hasil perhitungan poin satu achievement
+type PointEvaluation struct {
+	Points         int        `json:"points"`
+	RuleSetID      string     `json:"rule_set_id,omitempty"`
+	RuleSetVersion int        `json:"rule_set_version"`
+	MatchedRule    *PointRule `json:"matched_rule"`
+}
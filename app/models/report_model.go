@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type ReportStudentResponse struct {
 	Student     StudentReportInfo        `json:"student"`
 	Achievements []StudentAchievementDTO `json:"achievements"`
@@ -19,8 +21,12 @@ type StudentAchievementDTO struct {
 	Title  string `json:"title"`
 	Type   string `json:"type"`
 	Level  string `json:"level"`
+	Detail string `json:"detail"` // ringkasan details sesuai jenis achievement
 	Points int    `json:"points"`
 	Status string `json:"status"`
+	Role   string `json:"role,omitempty"` // ketua / anggota untuk achievement beregu
+
+	RevocationReason *string `json:"revocation_reason,omitempty"` // diisi jika status revoked
 }
 
 type StudentSummary struct {
@@ -28,3 +34,107 @@ type StudentSummary struct {
 	TotalPoints       int            `json:"total_points"`
 	CompetitionLevels map[string]int `json:"competition_levels"`
 }
+
+type TopStudent struct {
+	StudentID string `json:"student_id"`
+	Name      string `json:"name"`
+	Points    int    `json:"points"`
+}
+
+type ReportStatistics struct {
+	Total             int            `json:"total"`
+	PerType           map[string]int `json:"per_type"`
+	PerMonth          map[string]int `json:"per_month"`
+	CompetitionLevels map[string]int `json:"competition_levels"`
+	TopStudents       []TopStudent   `json:"top_students"`
+
+	Filters StatisticsFilter `json:"filters"` // filter yang dipakai
+}
+
+// PointHolder: satu penerima poin dari achievement verified.
+// MemberCount = 0 untuk achievement individu (poin ke pemilik).
+type PointHolder struct {
+	MongoID     string
+	StudentID   string
+	MemberCount int
+	VerifiedAt  *time.Time
+}
+
+// AchievementPoints: poin satu achievement (dari Mongo)
+type AchievementPoints struct {
+	Points    int
+	PointRule string
+}
+
+// AchievementAggregate: hasil agregasi statistik dari Mongo
+type AchievementAggregate struct {
+	Total             int
+	PerType           map[string]int
+	PerMonth          map[string]int
+	CompetitionLevels map[string]int
+	Points            map[string]AchievementPoints // mongo id -> poin, semua achievement yang lolos filter
+}
+
+// Pilihan tanggal untuk filter periode statistik
+const (
+	StatisticsDateEvent    = "event"    // tanggal kegiatan (details)
+	StatisticsDateVerified = "verified" // tanggal verifikasi dosen wali
+)
+
+// StatisticsFilter: query parameter GET /reports/statistics.
+// Filter mahasiswa (program_study, academic_year, advisor_id) berlaku untuk
+// penerima poin; achievement beregu ikut dihitung jika salah satu anggotanya cocok.
+type StatisticsFilter struct {
+	From         string `query:"from" json:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To           string `query:"to" json:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DateField    string `query:"date_field" json:"date_field,omitempty" validate:"omitempty,oneof=event verified"`
+	ProgramStudy string `query:"program_study" json:"program_study,omitempty" validate:"max=100"`
+	AcademicYear string `query:"academic_year" json:"academic_year,omitempty" validate:"max=20"`
+	AdvisorID    string `query:"advisor_id" json:"advisor_id,omitempty" validate:"omitempty,uuid"`
+	Type         string `query:"type" json:"type,omitempty" validate:"omitempty,achievement_type"`
+	Level        string `query:"level" json:"level,omitempty" validate:"omitempty,oneof=local regional national international unknown"`
+}
+
+// ByVerifiedDate: periode diterapkan ke verified_at (SQL), bukan tanggal kegiatan
+func (f StatisticsFilter) ByVerifiedDate() bool {
+	return f.DateField == StatisticsDateVerified
+}
+
+// LeaderboardEntry: satu mahasiswa di leaderboard poin verified.
+// Urutan: poin, jumlah achievement, lalu NIM (naik). Poin sama = rank sama
+// (1, 1, 3, ...), jadi urutan halaman tetap stabil walau ada seri.
+type LeaderboardEntry struct {
+	Rank         int    `json:"rank"`
+	ID           string `json:"id"`
+	StudentID    string `json:"student_id"`
+	Name         string `json:"name"`
+	ProgramStudy string `json:"program_study"`
+	AcademicYear string `json:"academic_year"`
+	Points       int    `json:"points"`
+	Achievements int    `json:"achievements"`
+}
+
+// Pagination: query ?page= & ?limit=
+type Pagination struct {
+	Page  int `query:"page" json:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type PageInfo struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// ProgramComparison: perbandingan antar program studi
+type ProgramComparison struct {
+	ProgramStudy           string  `json:"program_study"`
+	Students               int     `json:"students"`
+	ActiveStudents         int     `json:"active_students"` // minimal satu achievement verified
+	ActiveShare            float64 `json:"active_share"`    // active_students / students
+	TotalAchievements      int     `json:"total_achievements"`
+	TotalPoints            int     `json:"total_points"`
+	AchievementsPerStudent float64 `json:"achievements_per_student"`
+	PointsPerStudent       float64 `json:"points_per_student"`
+}
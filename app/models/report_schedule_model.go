@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Jenis laporan yang bisa dijadwalkan
+const (
+	ReportTypeStatistics        = "statistics"
+	ReportTypeLeaderboard       = "leaderboard"
+	ReportTypeProgramComparison = "program_comparison"
+	ReportTypeAccreditation     = "accreditation"
+)
+
+// Periode relatif, dihitung ulang setiap kali jadwal berjalan dan
+// menggantikan filters.from / filters.to
+const (
+	ReportPeriodPreviousWeek  = "previous_week"  // Senin s/d Minggu minggu lalu
+	ReportPeriodPreviousMonth = "previous_month" // bulan kalender sebelumnya
+	ReportPeriodAcademicYear  = "academic_year"  // 1 Agustus s/d tanggal jadwal berjalan
+)
+
+// Status hasil laporan (arsip)
+const (
+	ReportRunPending   = "pending"
+	ReportRunRunning   = "running"
+	ReportRunCompleted = "completed"
+	ReportRunFailed    = "failed"
+)
+
+// Pemicu pembuatan laporan
+const (
+	ReportTriggerSchedule = "schedule"
+	ReportTriggerManual   = "manual"
+)
+
+// ReportSchedule: definisi laporan berkala. Filters memakai filter yang sama
+// dengan /reports/statistics; laporan accreditation hanya memakai
+// program_study dan date_field (TS = tahun akademik saat jadwal berjalan).
+type ReportSchedule struct {
+	ID         string           `json:"id" db:"id"`
+	Name       string           `json:"name" db:"name"`
+	ReportType string           `json:"report_type" db:"report_type"`
+	Filters    StatisticsFilter `json:"filters" db:"filters"`
+	Period     string           `json:"period" db:"period"`
+	Format     string           `json:"format" db:"format"`
+	Cron       string           `json:"cron" db:"cron"`
+	Enabled    bool             `json:"enabled" db:"enabled"`
+	CreatedBy  *string          `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at" db:"updated_at"`
+	LastRunAt  *time.Time       `json:"last_run_at" db:"last_run_at"`
+	NextRunAt  *time.Time       `json:"next_run_at" db:"-"` // dihitung dari cron
+}
+
+// ReportRun: satu hasil laporan di arsip. File di storage tidak pernah
+// ditulis ulang, jadi isi laporan tetap walau data achievement berubah.
+type ReportRun struct {
+	ID           string           `json:"id" db:"id"`
+	ScheduleID   *string          `json:"schedule_id" db:"schedule_id"`
+	ReportType   string           `json:"report_type" db:"report_type"`
+	Filters      StatisticsFilter `json:"filters" db:"filters"` // filter final (periode sudah dihitung)
+	Format       string           `json:"format" db:"format"`
+	Trigger      string           `json:"trigger" db:"trigger"`
+	Status       string           `json:"status" db:"status"`
+	ScheduledFor *time.Time       `json:"scheduled_for" db:"scheduled_for"`
+	StorageKey   string           `json:"-" db:"storage_key"`
+	FileName     string           `json:"file_name" db:"file_name"`
+	ContentType  string           `json:"content_type" db:"content_type"`
+	Size         int64            `json:"size" db:"size"`
+	Checksum     string           `json:"checksum" db:"checksum"` // sha256 isi file
+	Error        *string          `json:"error" db:"error"`
+	CreatedBy    *string          `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+	StartedAt    *time.Time       `json:"started_at" db:"started_at"`
+	FinishedAt   *time.Time       `json:"finished_at" db:"finished_at"`
+}
+
+// ReportRunQuery: query parameter GET /reports/archive
+type ReportRunQuery struct {
+	ScheduleID string `query:"schedule_id" json:"schedule_id" validate:"omitempty,uuid"`
+	ReportType string `query:"report_type" json:"report_type" validate:"omitempty,oneof=statistics leaderboard program_comparison accreditation"`
+	Status     string `query:"status" json:"status" validate:"omitempty,oneof=pending running completed failed"`
+}
+
+// ===== REQUEST BODY (CREATE / UPDATE SCHEDULE) =====
+type ReportScheduleRequest struct {
+	Name       string           `json:"name" validate:"required,notblank,max=100"`
+	ReportType string           `json:"report_type" validate:"required,oneof=statistics leaderboard program_comparison accreditation"`
+	Filters    StatisticsFilter `json:"filters"`
+	Period     string           `json:"period" validate:"omitempty,oneof=previous_week previous_month academic_year"`
+	Format     string           `json:"format" validate:"required,oneof=json csv xlsx"`
+	Cron       string           `json:"cron" validate:"required,cron"` // 5 field, boleh diawali CRON_TZ=Asia/Jakarta
+	Enabled    *bool            `json:"enabled"`                       // default true
+}
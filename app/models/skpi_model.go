@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SKPIDocument: nomor dokumen SKPI (Surat Keterangan Pendamping Ijazah)
+type SKPIDocument struct {
+	ID        string    `json:"id" db:"id"`
+	StudentID string    `json:"student_id" db:"student_id"`
+	Number    string    `json:"number" db:"number"`
+	IssuedBy  *string   `json:"issued_by" db:"issued_by"`
+	IssuedAt  time.Time `json:"issued_at" db:"issued_at"`
+}
+
+// ===== REQUEST BODY (SKPI BATCH) =====
+// Satu angkatan (academicYear, bisa dipersempit dengan programStudy)
+// atau daftar mahasiswa yang lulus (studentIds).
+type SKPIBatchRequest struct {
+	AcademicYear string   `json:"academicYear" validate:"max=20"`
+	ProgramStudy string   `json:"programStudy" validate:"max=100"`
+	StudentIDs   []string `json:"studentIds" validate:"max=500,dive,uuid"`
+}
@@ -12,8 +12,8 @@ type User struct {
 }
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"notblank"`
+	Password string `json:"password" validate:"required"`
 }
 
 type AuthUserResponse struct {
@@ -26,23 +26,23 @@ type AuthUserResponse struct {
 
 // Admin create user
 type CreateUserRequest struct {
-    Username string `json:"username"`
-    Email    string `json:"email"`
-    Password string `json:"password"`
-    FullName string `json:"full_name"`
-    RoleID   string `json:"role_id"`
+    Username string `json:"username" validate:"notblank,min=3,max=50"`
+    Email    string `json:"email" validate:"required,email,max=100"`
+    Password string `json:"password" validate:"required,min=8,max=72"`
+    FullName string `json:"full_name" validate:"notblank,max=100"`
+    RoleID   string `json:"role_id" validate:"required,uuid"`
 }
 
 // Admin update user
 type UpdateUserRequest struct {
-    Email    string `json:"email"`
-    FullName string `json:"full_name"`
-    Password string `json:"password"`
-    RoleID   string `json:"role_id"`
+    Email    string `json:"email" validate:"omitempty,email,max=100"`
+    FullName string `json:"full_name" validate:"max=100"`
+    Password string `json:"password" validate:"omitempty,min=8,max=72"`
+    RoleID   string `json:"role_id" validate:"omitempty,uuid"`
     IsActive *bool  `json:"is_active"`
 }
 
 // Admin assign role
 type UpdateUserRoleRequest struct {
-    RoleID string `json:"role_id"`
+    RoleID string `json:"role_id" validate:"required,uuid"`
 }
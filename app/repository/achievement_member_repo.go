@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AchievementMemberRepository struct {
+	DB *sql.DB
+}
+
+func NewAchievementMemberRepository(db *sql.DB) *AchievementMemberRepository {
+	return &AchievementMemberRepository{DB: db}
+}
+
+// ================= REPLACE MEMBERS =================
+// hapus semua anggota lama lalu insert daftar baru (dalam satu transaksi)
+
+func (r *AchievementMemberRepository) ReplaceMembers(mongoID string, members []models.AchievementMember) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceMembersTx(tx, mongoID, members); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaceMembersTx dipakai juga saat reference dibuat, supaya achievement
+// beregu tidak pernah tersimpan tanpa anggota
+func replaceMembersTx(tx *sql.Tx, mongoID string, members []models.AchievementMember) error {
+	if _, err := tx.Exec(`DELETE FROM achievement_members WHERE mongo_achievement_id = $1`, mongoID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO achievement_members
+		(id, mongo_achievement_id, student_id, role, confirmed_at, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`
+
+	for i := range members {
+		m := &members[i]
+		m.ID = uuid.NewString()
+		m.MongoID = mongoID
+		m.CreatedAt = time.Now()
+
+		if _, err := tx.Exec(
+			query,
+			m.ID,
+			m.MongoID,
+			m.StudentID,
+			m.Role,
+			m.ConfirmedAt,
+			m.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ================= GET BY ACHIEVEMENT =================
+
+func (r *AchievementMemberRepository) GetByMongoID(mongoID string) ([]models.AchievementMember, error) {
+	query := `
+		SELECT id, mongo_achievement_id, student_id, role, confirmed_at, created_at
+		FROM achievement_members
+		WHERE mongo_achievement_id = $1
+		ORDER BY role DESC, created_at
+	`
+
+	rows, err := r.DB.Query(query, mongoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMembers(rows)
+}
+
+// GetByMongoIDs mengambil anggota untuk banyak achievement sekaligus,
+// dikelompokkan per mongo_achievement_id
+func (r *AchievementMemberRepository) GetByMongoIDs(mongoIDs []string) (map[string][]models.AchievementMember, error) {
+	result := make(map[string][]models.AchievementMember)
+	if len(mongoIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, mongo_achievement_id, student_id, role, confirmed_at, created_at
+		FROM achievement_members
+		WHERE mongo_achievement_id = ANY($1)
+		ORDER BY role DESC, created_at
+	`
+
+	rows, err := r.DB.Query(query, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanMembers(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range list {
+		result[m.MongoID] = append(result[m.MongoID], m)
+	}
+
+	return result, nil
+}
+
+func (r *AchievementMemberRepository) IsMember(mongoID, studentID string) (bool, error) {
+	query := `
+		SELECT COUNT(1)
+		FROM achievement_members
+		WHERE mongo_achievement_id = $1 AND student_id = $2
+	`
+
+	var count int
+	if err := r.DB.QueryRow(query, mongoID, studentID).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ================= CONFIRM =================
+
+func (r *AchievementMemberRepository) Confirm(mongoID, studentID string) error {
+	query := `
+		UPDATE achievement_members
+		SET confirmed_at = NOW()
+		WHERE mongo_achievement_id = $1
+		  AND student_id = $2
+		  AND confirmed_at IS NULL
+	`
+
+	res, err := r.DB.Exec(query, mongoID, studentID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *AchievementMemberRepository) CountUnconfirmed(mongoID string) (int, error) {
+	query := `
+		SELECT COUNT(1)
+		FROM achievement_members
+		WHERE mongo_achievement_id = $1
+		  AND confirmed_at IS NULL
+	`
+
+	var count int
+	err := r.DB.QueryRow(query, mongoID).Scan(&count)
+	return count, err
+}
+
+func scanMembers(rows *sql.Rows) ([]models.AchievementMember, error) {
+	var list []models.AchievementMember
+	for rows.Next() {
+		var m models.AchievementMember
+		if err := rows.Scan(
+			&m.ID,
+			&m.MongoID,
+			&m.StudentID,
+			&m.Role,
+			&m.ConfirmedAt,
+			&m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+
+	return list, nil
+}
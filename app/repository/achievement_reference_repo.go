@@ -2,11 +2,14 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"pbluas/app/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type AchievementReferenceRepository struct {
@@ -43,16 +46,61 @@ func (r *AchievementReferenceRepository) Create(ref *models.AchievementReference
 	return err
 }
 
+// CreateWithMembers: reference + anggota tim dalam satu transaksi
+func (r *AchievementReferenceRepository) CreateWithMembers(ref *models.AchievementReference, members []models.AchievementMember) error {
+	ref.ID = uuid.NewString()
+	ref.Status = "draft"
+	ref.CreatedAt = time.Now()
+	ref.UpdatedAt = time.Now()
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO achievement_references
+		(id, student_id, mongo_achievement_id, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`,
+		ref.ID,
+		ref.StudentID,
+		ref.MongoID,
+		ref.Status,
+		ref.CreatedAt,
+		ref.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(members) > 0 {
+		if err := replaceMembersTx(tx, ref.MongoID, members); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 // ================= GET BY STUDENT (Mahasiswa) =================
+// termasuk achievement beregu di mana student terdaftar sebagai anggota
 
 func (r *AchievementReferenceRepository) GetByStudentID(studentID string) ([]models.AchievementReference, error) {
 	query := `
 		SELECT 
 			id, student_id, mongo_achievement_id, status,
 			submitted_at, verified_at, verified_by, rejection_note,
+			revoked_at, revoked_by, revocation_reason,
 			created_at, updated_at
 		FROM achievement_references
-		WHERE student_id = $1
+		WHERE (
+			student_id = $1
+			OR mongo_achievement_id IN (
+				SELECT mongo_achievement_id FROM achievement_members WHERE student_id = $1
+			)
+		)
 		  AND status != 'deleted'
 	`
 
@@ -74,6 +122,9 @@ func (r *AchievementReferenceRepository) GetByStudentID(studentID string) ([]mod
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.RevokedAt,
+			&ref.RevokedBy,
+			&ref.RevocationReason,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		); err != nil {
@@ -92,6 +143,7 @@ func (r *AchievementReferenceRepository) GetAll() ([]models.AchievementReference
 		SELECT 
 			id, student_id, mongo_achievement_id, status,
 			submitted_at, verified_at, verified_by, rejection_note,
+			revoked_at, revoked_by, revocation_reason,
 			created_at, updated_at
 		FROM achievement_references
 		WHERE status != 'deleted'
@@ -115,6 +167,9 @@ func (r *AchievementReferenceRepository) GetAll() ([]models.AchievementReference
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.RevokedAt,
+			&ref.RevokedBy,
+			&ref.RevocationReason,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		); err != nil {
@@ -128,17 +183,31 @@ func (r *AchievementReferenceRepository) GetAll() ([]models.AchievementReference
 
 // ================= GET BY ADVISOR (Dosen Wali) =================
 
+// GetByAdvisorUserID: achievement milik mahasiswa perwalian, termasuk
+// achievement beregu yang salah satu anggotanya mahasiswa perwalian
+
 func (r *AchievementReferenceRepository) GetByAdvisorUserID(userID string) ([]models.AchievementReference, error) {
 	query := `
 		SELECT 
 			ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
 			ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+			ar.revoked_at, ar.revoked_by, ar.revocation_reason,
 			ar.created_at, ar.updated_at
 		FROM achievement_references ar
-		JOIN students s ON s.id = ar.student_id
-		JOIN lecturers l ON l.id = s.advisor_id
-		WHERE l.user_id = $1
-		  AND ar.status != 'deleted'
+		WHERE ar.status != 'deleted'
+		  AND EXISTS (
+			SELECT 1
+			FROM students s
+			JOIN lecturers l ON l.id = s.advisor_id
+			WHERE l.user_id = $1
+			  AND (
+				s.id = ar.student_id
+				OR s.id IN (
+					SELECT student_id FROM achievement_members
+					WHERE mongo_achievement_id = ar.mongo_achievement_id
+				)
+			  )
+		  )
 	`
 
 	rows, err := r.DB.Query(query, userID)
@@ -159,6 +228,9 @@ func (r *AchievementReferenceRepository) GetByAdvisorUserID(userID string) ([]mo
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.RevokedAt,
+			&ref.RevokedBy,
+			&ref.RevocationReason,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		); err != nil {
@@ -175,6 +247,7 @@ func (r *AchievementReferenceRepository) GetByMongoID(mongoID string) (*models.A
 		SELECT 
 			id, student_id, mongo_achievement_id, status,
 			submitted_at, verified_at, verified_by, rejection_note,
+			revoked_at, revoked_by, revocation_reason,
 			created_at, updated_at
 		FROM achievement_references
 		WHERE mongo_achievement_id = $1
@@ -191,6 +264,9 @@ func (r *AchievementReferenceRepository) GetByMongoID(mongoID string) (*models.A
 		&ref.VerifiedAt,
 		&ref.VerifiedBy,
 		&ref.RejectionNote,
+		&ref.RevokedAt,
+		&ref.RevokedBy,
+		&ref.RevocationReason,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
 	)
@@ -219,6 +295,34 @@ func (r *AchievementReferenceRepository) IsAdvisorOfStudent(userID, studentID st
 	return count > 0, nil
 }
 
+// IsAdvisorOfAchievement: dosen wali pemilik atau salah satu anggota tim
+func (r *AchievementReferenceRepository) IsAdvisorOfAchievement(userID, mongoID string) (bool, error) {
+	query := `
+		SELECT COUNT(1)
+		FROM students s
+		JOIN lecturers l ON l.id = s.advisor_id
+		WHERE l.user_id = $2
+		  AND (
+			s.id IN (
+				SELECT student_id FROM achievement_references
+				WHERE mongo_achievement_id = $1
+			)
+			OR s.id IN (
+				SELECT student_id FROM achievement_members
+				WHERE mongo_achievement_id = $1
+			)
+		  )
+	`
+
+	var count int
+	err := r.DB.QueryRow(query, mongoID, userID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func (r *AchievementReferenceRepository) SoftDelete(id string) error {
 	query := `
 		UPDATE achievement_references
@@ -317,9 +421,14 @@ func (r *AchievementReferenceRepository) Reject(id string, rejectedBy string, no
 
 func (r *AchievementReferenceRepository)GetByStudentIDForReport(studentID string) ([]models.AchievementReference, error) {
 	query := `
-		SELECT id, student_id, mongo_achievement_id, status
+		SELECT id, student_id, mongo_achievement_id, status, revocation_reason
 		FROM achievement_references
-		WHERE student_id = $1
+		WHERE (
+			student_id = $1
+			OR mongo_achievement_id IN (
+				SELECT mongo_achievement_id FROM achievement_members WHERE student_id = $1
+			)
+		)
 		  AND status != 'deleted'
 	`
 
@@ -337,6 +446,7 @@ func (r *AchievementReferenceRepository)GetByStudentIDForReport(studentID string
 			&ref.StudentID,
 			&ref.MongoID,
 			&ref.Status,
+			&ref.RevocationReason,
 		); err != nil {
 			return nil, err
 		}
@@ -346,3 +456,283 @@ func (r *AchievementReferenceRepository)GetByStudentIDForReport(studentID string
 	return list, nil
 }
 
+
+// ================= GET ALL INCLUDING DELETED (Consistency Check) =================
+
+func (r *AchievementReferenceRepository) GetAllIncludingDeleted() ([]models.AchievementReference, error) {
+	query := `
+		SELECT 
+			id, student_id, mongo_achievement_id, status,
+			submitted_at, verified_at, verified_by, rejection_note,
+			revoked_at, revoked_by, revocation_reason,
+			created_at, updated_at
+		FROM achievement_references
+		ORDER BY created_at
+	`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AchievementReference
+	for rows.Next() {
+		var ref models.AchievementReference
+		if err := rows.Scan(
+			&ref.ID,
+			&ref.StudentID,
+			&ref.MongoID,
+			&ref.Status,
+			&ref.SubmittedAt,
+			&ref.VerifiedAt,
+			&ref.VerifiedBy,
+			&ref.RejectionNote,
+			&ref.RevokedAt,
+			&ref.RevokedBy,
+			&ref.RevocationReason,
+			&ref.CreatedAt,
+			&ref.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, ref)
+	}
+
+	return list, nil
+}
+
+// ================= GET BY MONGO IDS (batch) =================
+
+func (r *AchievementReferenceRepository) GetByMongoIDs(mongoIDs []string) ([]models.AchievementReference, error) {
+	if len(mongoIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT 
+			id, student_id, mongo_achievement_id, status,
+			submitted_at, verified_at, verified_by, rejection_note,
+			revoked_at, revoked_by, revocation_reason,
+			created_at, updated_at
+		FROM achievement_references
+		WHERE mongo_achievement_id = ANY($1)
+		  AND status != 'deleted'
+	`
+
+	rows, err := r.DB.Query(query, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AchievementReference
+	for rows.Next() {
+		var ref models.AchievementReference
+		if err := rows.Scan(
+			&ref.ID,
+			&ref.StudentID,
+			&ref.MongoID,
+			&ref.Status,
+			&ref.SubmittedAt,
+			&ref.VerifiedAt,
+			&ref.VerifiedBy,
+			&ref.RejectionNote,
+			&ref.RevokedAt,
+			&ref.RevokedBy,
+			&ref.RevocationReason,
+			&ref.CreatedAt,
+			&ref.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, ref)
+	}
+
+	return list, nil
+}
+
+func (r *AchievementReferenceRepository) Revoke(id string, revokedBy string, reason string) error {
+	query := `
+		UPDATE achievement_references
+		SET status = 'revoked',
+		    revoked_at = NOW(),
+		    revoked_by = $2,
+		    revocation_reason = $3,
+		    updated_at = NOW()
+		WHERE id = $1
+		  AND status = 'verified'
+	`
+
+	res, err := r.DB.Exec(query, id, revokedBy, reason)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ================= POINT HOLDERS (Statistics) =================
+
+// GetVerifiedPointHolders: semua penerima poin achievement verified dalam
+// satu query. Achievement beregu menghasilkan satu baris per anggota,
+// achievement individu satu baris untuk pemilik.
+// MemberCount dihitung sebelum filter mahasiswa supaya pembagian poin
+// achievement beregu tetap benar.
+func (r *AchievementReferenceRepository) GetVerifiedPointHolders(f models.StatisticsFilter) ([]models.PointHolder, error) {
+	var inner, outer []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.ByVerifiedDate() {
+		if f.From != "" {
+			inner = append(inner, "ar.verified_at >= "+arg(f.From)+"::date")
+		}
+		if f.To != "" {
+			inner = append(inner, "ar.verified_at < "+arg(f.To)+"::date + 1")
+		}
+	}
+	if f.ProgramStudy != "" {
+		outer = append(outer, "LOWER(s.program_study) = LOWER("+arg(f.ProgramStudy)+")")
+	}
+	if f.AcademicYear != "" {
+		outer = append(outer, "s.academic_year = "+arg(f.AcademicYear))
+	}
+	if f.AdvisorID != "" {
+		outer = append(outer, "s.advisor_id = "+arg(f.AdvisorID)+"::uuid")
+	}
+
+	query := `
+		SELECT h.mongo_achievement_id, h.student_id, h.member_count, h.verified_at
+		FROM (
+			SELECT
+				ar.mongo_achievement_id,
+				COALESCE(am.student_id, ar.student_id) AS student_id,
+				COUNT(am.student_id) OVER (PARTITION BY ar.mongo_achievement_id) AS member_count,
+				ar.verified_at
+			FROM achievement_references ar
+			LEFT JOIN achievement_members am ON am.mongo_achievement_id = ar.mongo_achievement_id
+			WHERE ar.status = 'verified'` + andAll(inner) + `
+		) h
+		LEFT JOIN students s ON s.id = h.student_id
+		WHERE TRUE` + andAll(outer)
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PointHolder
+	for rows.Next() {
+		var h models.PointHolder
+		if err := rows.Scan(&h.MongoID, &h.StudentID, &h.MemberCount, &h.VerifiedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+
+	return list, rows.Err()
+}
+
+func andAll(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(conds, " AND ")
+}
+
+// ================= DASHBOARD DOSEN WALI =================
+
+// CountByStudentStatus: jumlah achievement per mahasiswa per status, beserta
+// jumlah yang dibuat sejak `since`. Achievement beregu dihitung untuk setiap
+// anggota (sama seperti poin di GetVerifiedPointHolders).
+func (r *AchievementReferenceRepository) CountByStudentStatus(studentIDs []string, since time.Time) ([]models.StudentStatusCount, error) {
+	if len(studentIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT h.student_id, ar.status, COUNT(*), COUNT(*) FILTER (WHERE ar.created_at >= $2)
+		FROM (
+			SELECT student_id, mongo_achievement_id
+			FROM achievement_references
+			WHERE student_id = ANY($1::uuid[])
+			UNION
+			SELECT student_id, mongo_achievement_id
+			FROM achievement_members
+			WHERE student_id = ANY($1::uuid[])
+		) h
+		JOIN achievement_references ar ON ar.mongo_achievement_id = h.mongo_achievement_id
+		WHERE ar.status != 'deleted'
+		GROUP BY h.student_id, ar.status
+	`
+
+	rows, err := r.DB.Query(query, pq.Array(studentIDs), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.StudentStatusCount
+	for rows.Next() {
+		var c models.StudentStatusCount
+		if err := rows.Scan(&c.StudentID, &c.Status, &c.Total, &c.SinceCount); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+}
+
+// GetSubmittedByStudentIDs: antrean verifikasi, paling lama di atas
+func (r *AchievementReferenceRepository) GetSubmittedByStudentIDs(studentIDs []string) ([]models.AchievementReference, error) {
+	if len(studentIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, student_id, mongo_achievement_id, status, submitted_at, created_at
+		FROM achievement_references
+		WHERE student_id = ANY($1::uuid[])
+		  AND status = 'submitted'
+		ORDER BY submitted_at ASC NULLS LAST, created_at ASC
+	`
+
+	rows, err := r.DB.Query(query, pq.Array(studentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AchievementReference
+	for rows.Next() {
+		var ref models.AchievementReference
+		if err := rows.Scan(
+			&ref.ID,
+			&ref.StudentID,
+			&ref.MongoID,
+			&ref.Status,
+			&ref.SubmittedAt,
+			&ref.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, ref)
+	}
+
+	return list, rows.Err()
+}
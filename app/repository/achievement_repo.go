@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"strings"
 	"time"
 
 	"pbluas/app/models"
@@ -9,6 +12,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AchievementRepository struct {
@@ -34,6 +38,13 @@ func (r *AchievementRepository) Create(ctx context.Context, a *models.Achievemen
 	return nil
 }
 
+// DeleteByID menghapus dokumen permanen; hanya untuk membatalkan Create
+// yang gagal disimpan di Postgres
+func (r *AchievementRepository) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
 func (r *AchievementRepository) FindByIDs(ctx context.Context, ids []string) ([]models.Achievement, error) {
 	var objIDs []primitive.ObjectID
 	for _, id := range ids {
@@ -82,6 +93,7 @@ func (r *AchievementRepository) UpdateByID(
 	id string,
 	req models.AchievementCreateRequest,
 	points int,
+	pointRuleVersion int,
 ) error {
 
 	oid, err := primitive.ObjectIDFromHex(id)
@@ -97,6 +109,8 @@ func (r *AchievementRepository) UpdateByID(
 			"details":         req.Details,
 			"tags":            req.Tags,
 			"points": points,
+			"pointRuleVersion": pointRuleVersion,
+			"pointRule":       req.PointRule,
 			"updatedAt":       time.Now(),
 		},
 	}
@@ -110,9 +124,13 @@ func (r *AchievementRepository) UpdateByID(
 	return err
 }
 
+// AddAttachment menambah satu attachment. Sama seperti SetAttachments, hanya
+// berhasil jika updatedAt belum berubah sejak dokumen dibaca, supaya batas
+// total ukuran yang dicek dari dokumen itu tidak terlewati upload paralel.
 func (r *AchievementRepository) AddAttachment(
 	ctx context.Context,
 	id string,
+	readAt time.Time,
 	attachment models.AchievementAttachment,
 ) error {
 
@@ -130,6 +148,142 @@ func (r *AchievementRepository) AddAttachment(
 		},
 	}
 
+	res, err := r.Collection.UpdateOne(
+		ctx,
+		bson.M{"_id": oid, "updatedAt": readAt},
+		update,
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrAttachmentsChanged
+	}
+
+	return nil
+}
+
+// ListOwnership mengambil semua dokumen achievement, hanya _id dan studentId
+// (dipakai consistency checker)
+func (r *AchievementRepository) ListOwnership(ctx context.Context) ([]models.Achievement, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "studentId": 1})
+
+	cursor, err := r.Collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Achievement
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r *AchievementRepository) UpdateStudentID(ctx context.Context, id string, studentID string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"studentId": studentID,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	_, err = r.Collection.UpdateOne(
+		ctx,
+		bson.M{"_id": oid},
+		update,
+	)
+
+	return err
+}
+
+// FindDuplicateCandidates mencari achievement lain dengan eventDate,
+// competitionName atau title yang sama (case-insensitive).
+// Skor kemiripan dihitung di service.
+func (r *AchievementRepository) FindDuplicateCandidates(
+	ctx context.Context,
+	a *models.Achievement,
+) ([]models.Achievement, error) {
+
+	var or []bson.M
+	if a.Details.EventDate != "" {
+		or = append(or, bson.M{"details.eventDate": a.Details.EventDate})
+	}
+	if a.Details.CompetitionName != "" {
+		or = append(or, bson.M{"details.competitionName": caseInsensitive(a.Details.CompetitionName)})
+	}
+	if a.Title != "" {
+		or = append(or, bson.M{"title": caseInsensitive(a.Title)})
+	}
+	if len(or) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{"$or": or}
+	if !a.ID.IsZero() {
+		filter["_id"] = bson.M{"$ne": a.ID}
+	}
+
+	opts := options.Find().SetLimit(200)
+
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Achievement
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r *AchievementRepository) SetDuplicateFlags(ctx context.Context, id string, flags []models.DuplicateFlag) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Collection.UpdateOne(
+		ctx,
+		bson.M{"_id": oid},
+		bson.M{"$set": bson.M{"duplicateFlags": flags}},
+	)
+
+	return err
+}
+
+func caseInsensitive(value string) primitive.Regex {
+	return primitive.Regex{
+		Pattern: "^" + regexp.QuoteMeta(strings.TrimSpace(value)) + "$",
+		Options: "i",
+	}
+}
+
+// UpdatePoints dipakai job rekalkulasi poin
+func (r *AchievementRepository) UpdatePoints(ctx context.Context, id string, points int, pointRuleVersion int) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"points":           points,
+			"pointRuleVersion": pointRuleVersion,
+			"updatedAt":        time.Now(),
+		},
+	}
+
 	_, err = r.Collection.UpdateOne(
 		ctx,
 		bson.M{"_id": oid},
@@ -138,3 +292,376 @@ func (r *AchievementRepository) AddAttachment(
 
 	return err
 }
+
+// ListAttachments mengambil semua dokumen yang punya attachment,
+// hanya _id dan attachments (dipakai migrasi storage)
+func (r *AchievementRepository) ListAttachments(ctx context.Context) ([]models.Achievement, error) {
+	filter := bson.M{"attachments.0": bson.M{"$exists": true}}
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "attachments": 1})
+
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Achievement
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ErrAttachmentsChanged: dokumen diubah request lain sejak dibaca
+var ErrAttachmentsChanged = errors.New("achievement was modified concurrently, please retry")
+
+// SetAttachments mengganti seluruh daftar attachment (hapus / ganti / urutkan).
+// Hanya berhasil jika updatedAt masih sama dengan saat dokumen dibaca,
+// supaya perubahan dari request lain tidak tertimpa.
+func (r *AchievementRepository) SetAttachments(
+	ctx context.Context,
+	id string,
+	readAt time.Time,
+	attachments []models.AchievementAttachment,
+) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	if attachments == nil {
+		attachments = []models.AchievementAttachment{}
+	}
+
+	res, err := r.Collection.UpdateOne(
+		ctx,
+		bson.M{"_id": oid, "updatedAt": readAt},
+		bson.M{"$set": bson.M{
+			"attachments": attachments,
+			"updatedAt":   time.Now(),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrAttachmentsChanged
+	}
+
+	return nil
+}
+
+// attachmentMatch mencocokkan satu attachment berdasarkan id + storage key,
+// supaya hasil scan file lama tidak tertulis ke file pengganti.
+// Attachment lama (tanpa id / storageKey) dicocokkan lewat fileUrl.
+func attachmentMatch(att models.AchievementAttachment) bson.M {
+	if att.StorageKey == "" {
+		return bson.M{"fileUrl": att.FileURL, "storageKey": bson.M{"$exists": false}}
+	}
+	return bson.M{"id": att.ID, "storageKey": att.StorageKey}
+}
+
+// SetAttachmentScan menyimpan hasil scan malware satu attachment.
+// storageKey diisi jika file dipindah (karantina). updatedAt tidak diubah
+// karena ini bukan perubahan dari user.
+func (r *AchievementRepository) SetAttachmentScan(
+	ctx context.Context,
+	id string,
+	att models.AchievementAttachment,
+	status string,
+	signature string,
+	storageKey string,
+) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"attachments.$.scanStatus":    status,
+		"attachments.$.scanSignature": signature,
+		"attachments.$.scannedAt":     time.Now(),
+	}
+	if storageKey != "" {
+		set["attachments.$.storageKey"] = storageKey
+	}
+
+	_, err = r.Collection.UpdateOne(
+		ctx,
+		bson.M{"_id": oid, "attachments": bson.M{"$elemMatch": attachmentMatch(att)}},
+		bson.M{"$set": set},
+	)
+
+	return err
+}
+
+// ListUnscannedAttachments: dokumen yang punya attachment pending / belum discan
+func (r *AchievementRepository) ListUnscannedAttachments(ctx context.Context) ([]models.Achievement, error) {
+	filter := bson.M{"attachments": bson.M{"$elemMatch": bson.M{
+		"scanStatus": bson.M{"$nin": []string{models.ScanClean, models.ScanInfected}},
+	}}}
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "attachments": 1})
+
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Achievement
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindBySHA256 mencari achievement lain yang punya attachment dengan
+// salah satu hash yang diberikan
+func (r *AchievementRepository) FindBySHA256(ctx context.Context, hashes []string, excludeID primitive.ObjectID) ([]models.Achievement, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{
+		"_id":                bson.M{"$ne": excludeID},
+		"attachments.sha256": bson.M{"$in": hashes},
+	}
+	opts := options.Find().SetProjection(bson.M{
+		"_id": 1, "studentId": 1, "title": 1, "attachments": 1,
+	})
+
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []models.Achievement
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SetAttachmentThumbnail menyimpan key thumbnail satu attachment
+func (r *AchievementRepository) SetAttachmentThumbnail(
+	ctx context.Context,
+	id string,
+	att models.AchievementAttachment,
+	thumbnailKey string,
+) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Collection.UpdateOne(
+		ctx,
+		bson.M{"_id": oid, "attachments": bson.M{"$elemMatch": attachmentMatch(att)}},
+		bson.M{"$set": bson.M{"attachments.$.thumbnailKey": thumbnailKey}},
+	)
+
+	return err
+}
+
+// jumlah _id per pipeline agregasi, supaya dokumen $match tetap kecil
+const statisticsChunkSize = 5000
+
+type countBucket struct {
+	ID string `bson:"_id"`
+	N  int    `bson:"n"`
+}
+
+type statisticsFacet struct {
+	Total []struct {
+		N int `bson:"n"`
+	} `bson:"total"`
+	PerType           []countBucket `bson:"perType"`
+	PerMonth          []countBucket `bson:"perMonth"`
+	CompetitionLevels []countBucket `bson:"competitionLevels"`
+	Points            []struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Points    int                `bson:"points"`
+		PointRule string             `bson:"pointRule"`
+	} `bson:"points"`
+}
+
+// eventDateExpr: tanggal kegiatan sesuai jenis achievement
+// (eventDate, periodStart, lalu registrationDate), "" jika kosong
+var eventDateExpr = bson.M{"$cond": bson.A{
+	bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$details.eventDate", ""}}, ""}},
+	"$details.eventDate",
+	bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$details.periodStart", ""}}, ""}},
+		"$details.periodStart",
+		bson.M{"$ifNull": bson.A{"$details.registrationDate", ""}},
+	}},
+}}
+
+// statisticsMatch: filter statistik yang disimpan di Mongo
+// (jenis, tingkat, periode tanggal kegiatan)
+func statisticsMatch(objIDs []primitive.ObjectID, f models.StatisticsFilter) bson.M {
+	match := bson.M{"_id": bson.M{"$in": objIDs}}
+
+	if f.Type != "" {
+		match["achievementType"] = f.Type
+	}
+	if f.Level == "unknown" {
+		match["details.competitionLevel"] = bson.M{"$in": bson.A{"", nil}}
+	} else if f.Level != "" {
+		match["details.competitionLevel"] = f.Level
+	}
+
+	if !f.ByVerifiedDate() && (f.From != "" || f.To != "") {
+		// tanggal disimpan sebagai string YYYY-MM-DD, jadi cukup dibandingkan
+		cond := bson.A{bson.M{"$gt": bson.A{eventDateExpr, ""}}}
+		if f.From != "" {
+			cond = append(cond, bson.M{"$gte": bson.A{eventDateExpr, f.From}})
+		}
+		if f.To != "" {
+			cond = append(cond, bson.M{"$lte": bson.A{eventDateExpr, f.To}})
+		}
+		match["$expr"] = bson.M{"$and": cond}
+	}
+
+	return match
+}
+
+// AggregateStatistics menghitung statistik achievement dengan $facet,
+// per potongan statisticsChunkSize id (hasil tiap potongan dijumlahkan).
+func (r *AchievementRepository) AggregateStatistics(
+	ctx context.Context,
+	ids []string,
+	f models.StatisticsFilter,
+) (*models.AchievementAggregate, error) {
+	result := &models.AchievementAggregate{
+		PerType:           map[string]int{},
+		PerMonth:          map[string]int{},
+		CompetitionLevels: map[string]int{},
+		Points:            map[string]models.AchievementPoints{},
+	}
+
+	var objIDs []primitive.ObjectID
+	for _, id := range ids {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objIDs = append(objIDs, oid)
+	}
+
+	for start := 0; start < len(objIDs); start += statisticsChunkSize {
+		end := min(start+statisticsChunkSize, len(objIDs))
+		if err := r.aggregateStatisticsChunk(ctx, statisticsMatch(objIDs[start:end], f), result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (r *AchievementRepository) aggregateStatisticsChunk(
+	ctx context.Context,
+	match bson.M,
+	result *models.AchievementAggregate,
+) error {
+	count := func(key interface{}) bson.A {
+		return bson.A{bson.M{"$group": bson.M{"_id": key, "n": bson.M{"$sum": 1}}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: bson.M{
+			"total":    bson.A{bson.M{"$count": "n"}},
+			"perType":  count("$achievementType"),
+			"perMonth": count(bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$createdAt"}}),
+			// tingkat kosong dihitung sebagai "unknown"
+			"competitionLevels": count(bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$details.competitionLevel", ""}}, ""}},
+				"$details.competitionLevel",
+				"unknown",
+			}}),
+			"points": bson.A{
+				bson.M{"$project": bson.M{"_id": 1, "points": 1, "pointRule": 1}},
+			},
+		}}},
+	}
+
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []statisticsFacet
+	if err := cursor.All(ctx, &facets); err != nil {
+		return err
+	}
+	if len(facets) == 0 {
+		return nil
+	}
+
+	f := facets[0]
+	if len(f.Total) > 0 {
+		result.Total += f.Total[0].N
+	}
+	for _, b := range f.PerType {
+		result.PerType[b.ID] += b.N
+	}
+	for _, b := range f.PerMonth {
+		result.PerMonth[b.ID] += b.N
+	}
+	for _, b := range f.CompetitionLevels {
+		result.CompetitionLevels[b.ID] += b.N
+	}
+	for _, p := range f.Points {
+		result.Points[p.ID.Hex()] = models.AchievementPoints{Points: p.Points, PointRule: p.PointRule}
+	}
+
+	return nil
+}
+
+// FindLevelFacts: hanya jenis, tingkat dan tanggal achievement
+// (dipakai laporan akreditasi), per potongan statisticsChunkSize id
+func (r *AchievementRepository) FindLevelFacts(ctx context.Context, ids []string) ([]models.Achievement, error) {
+	var objIDs []primitive.ObjectID
+	for _, id := range ids {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objIDs = append(objIDs, oid)
+	}
+
+	opts := options.Find().SetProjection(bson.M{
+		"_id":                      1,
+		"achievementType":          1,
+		"details.competitionLevel": 1,
+		"details.eventDate":        1,
+		"details.periodStart":      1,
+		"details.registrationDate": 1,
+	})
+
+	var results []models.Achievement
+	for start := 0; start < len(objIDs); start += statisticsChunkSize {
+		end := min(start+statisticsChunkSize, len(objIDs))
+
+		cursor, err := r.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": objIDs[start:end]}}, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var chunk []models.Achievement
+		err = cursor.All(ctx, &chunk)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type AttachmentEventRepository struct {
+	DB *sql.DB
+}
+
+func NewAttachmentEventRepository(db *sql.DB) *AttachmentEventRepository {
+	return &AttachmentEventRepository{DB: db}
+}
+
+func (r *AttachmentEventRepository) Create(e *models.AttachmentEvent) error {
+	e.ID = uuid.NewString()
+	e.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO achievement_attachment_events
+		(id, mongo_achievement_id, attachment_id, action, file_name, actor_id, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`
+
+	_, err := r.DB.Exec(
+		query,
+		e.ID,
+		e.MongoID,
+		e.AttachmentID,
+		e.Action,
+		e.FileName,
+		e.ActorID,
+		e.CreatedAt,
+	)
+	return err
+}
+
+func (r *AttachmentEventRepository) GetByMongoID(mongoID string) ([]models.AttachmentEvent, error) {
+	query := `
+		SELECT id, mongo_achievement_id, attachment_id, action, file_name, actor_id, created_at
+		FROM achievement_attachment_events
+		WHERE mongo_achievement_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.DB.Query(query, mongoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AttachmentEvent
+	for rows.Next() {
+		var e models.AttachmentEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.MongoID,
+			&e.AttachmentID,
+			&e.Action,
+			&e.FileName,
+			&e.ActorID,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	return list, nil
+}
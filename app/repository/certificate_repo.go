@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type CertificateRepository struct {
+	DB *sql.DB
+}
+
+func NewCertificateRepository(db *sql.DB) *CertificateRepository {
+	return &CertificateRepository{DB: db}
+}
+
+// code 16 karakter base32 (80 bit), tanpa karakter yang mirip di QR / cetakan
+func newCertificateCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// GetOrCreate mengembalikan sertifikat achievement, membuat baru jika belum
+// ada. Code tidak berubah walaupun PDF diunduh berkali-kali.
+func (r *CertificateRepository) GetOrCreate(mongoID string, issuedBy string) (*models.AchievementCertificate, error) {
+	code, err := newCertificateCode()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.DB.Exec(`
+		INSERT INTO achievement_certificates (id, mongo_achievement_id, code, issued_by, issued_at)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (mongo_achievement_id) DO NOTHING
+	`, uuid.NewString(), mongoID, code, issuedBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanOne(`
+		SELECT id, mongo_achievement_id, code, issued_by, issued_at
+		FROM achievement_certificates
+		WHERE mongo_achievement_id = $1
+	`, mongoID)
+}
+
+func (r *CertificateRepository) GetByCode(code string) (*models.AchievementCertificate, error) {
+	return r.scanOne(`
+		SELECT id, mongo_achievement_id, code, issued_by, issued_at
+		FROM achievement_certificates
+		WHERE code = $1
+	`, code)
+}
+
+func (r *CertificateRepository) scanOne(query string, arg string) (*models.AchievementCertificate, error) {
+	var cert models.AchievementCertificate
+	err := r.DB.QueryRow(query, arg).Scan(
+		&cert.ID,
+		&cert.MongoID,
+		&cert.Code,
+		&cert.IssuedBy,
+		&cert.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cert, nil
+}
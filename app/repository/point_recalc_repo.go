@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type PointRecalcRepository struct {
+	DB *sql.DB
+}
+
+func NewPointRecalcRepository(db *sql.DB) *PointRecalcRepository {
+	return &PointRecalcRepository{DB: db}
+}
+
+// ================= JOB =================
+
+func (r *PointRecalcRepository) CreateJob(job *models.PointRecalcJob) error {
+	job.ID = uuid.NewString()
+	job.Status = models.RecalcStatusPending
+	job.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO point_recalc_jobs
+		(id, scope, scope_value, mode, status, created_by, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`
+
+	_, err := r.DB.Exec(
+		query,
+		job.ID,
+		job.Scope,
+		job.ScopeValue,
+		job.Mode,
+		job.Status,
+		job.CreatedBy,
+		job.CreatedAt,
+	)
+	return err
+}
+
+func (r *PointRecalcRepository) MarkRunning(id string, ruleSetVersion int) error {
+	query := `
+		UPDATE point_recalc_jobs
+		SET status = 'running',
+		    rule_set_version = $2,
+		    started_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.DB.Exec(query, id, ruleSetVersion)
+	return err
+}
+
+func (r *PointRecalcRepository) MarkFinished(id string, total, changed int, jobErr error) error {
+	status := models.RecalcStatusCompleted
+	var errText *string
+	if jobErr != nil {
+		status = models.RecalcStatusFailed
+		msg := jobErr.Error()
+		errText = &msg
+	}
+
+	query := `
+		UPDATE point_recalc_jobs
+		SET status = $2,
+		    total = $3,
+		    changed = $4,
+		    error = $5,
+		    finished_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.DB.Exec(query, id, status, total, changed, errText)
+	return err
+}
+
+func (r *PointRecalcRepository) GetJobs() ([]models.PointRecalcJob, error) {
+	query := `
+		SELECT id, scope, scope_value, mode, status, rule_set_version,
+		       total, changed, error, created_by, created_at, started_at, finished_at
+		FROM point_recalc_jobs
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PointRecalcJob
+	for rows.Next() {
+		var job models.PointRecalcJob
+		if err := rows.Scan(
+			&job.ID,
+			&job.Scope,
+			&job.ScopeValue,
+			&job.Mode,
+			&job.Status,
+			&job.RuleSetVersion,
+			&job.Total,
+			&job.Changed,
+			&job.Error,
+			&job.CreatedBy,
+			&job.CreatedAt,
+			&job.StartedAt,
+			&job.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, job)
+	}
+
+	return list, nil
+}
+
+func (r *PointRecalcRepository) GetJob(id string) (*models.PointRecalcJob, error) {
+	query := `
+		SELECT id, scope, scope_value, mode, status, rule_set_version,
+		       total, changed, error, created_by, created_at, started_at, finished_at
+		FROM point_recalc_jobs
+		WHERE id = $1
+	`
+
+	var job models.PointRecalcJob
+	err := r.DB.QueryRow(query, id).Scan(
+		&job.ID,
+		&job.Scope,
+		&job.ScopeValue,
+		&job.Mode,
+		&job.Status,
+		&job.RuleSetVersion,
+		&job.Total,
+		&job.Changed,
+		&job.Error,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// ================= ITEMS (audit per achievement) =================
+
+func (r *PointRecalcRepository) InsertItems(jobID string, items []models.PointRecalcItem) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO point_recalc_items
+		(id, job_id, mongo_achievement_id, student_id, status,
+		 old_points, new_points, old_rule_version, new_rule_version, applied)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range items {
+		it := &items[i]
+		it.ID = uuid.NewString()
+		it.JobID = jobID
+
+		if _, err := stmt.Exec(
+			it.ID,
+			it.JobID,
+			it.MongoID,
+			it.StudentID,
+			it.Status,
+			it.OldPoints,
+			it.NewPoints,
+			it.OldRuleVersion,
+			it.NewRuleVersion,
+			it.Applied,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetItems: changedOnly = hanya achievement yang poinnya berubah
+func (r *PointRecalcRepository) GetItems(jobID string, changedOnly bool) ([]models.PointRecalcItem, error) {
+	query := `
+		SELECT id, job_id, mongo_achievement_id, student_id, status,
+		       old_points, new_points, old_rule_version, new_rule_version, applied
+		FROM point_recalc_items
+		WHERE job_id = $1
+	`
+	if changedOnly {
+		query += ` AND old_points != new_points`
+	}
+	query += ` ORDER BY student_id, mongo_achievement_id`
+
+	rows, err := r.DB.Query(query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PointRecalcItem
+	for rows.Next() {
+		var it models.PointRecalcItem
+		if err := rows.Scan(
+			&it.ID,
+			&it.JobID,
+			&it.MongoID,
+			&it.StudentID,
+			&it.Status,
+			&it.OldPoints,
+			&it.NewPoints,
+			&it.OldRuleVersion,
+			&it.NewRuleVersion,
+			&it.Applied,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, it)
+	}
+
+	return list, nil
+}
+
+// ================= STUDENT TOTALS =================
+
+func (r *PointRecalcRepository) InsertStudentTotals(jobID string, totals []models.PointRecalcStudentTotal) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO point_recalc_student_totals
+		(job_id, student_id, old_total, new_total)
+		VALUES ($1,$2,$3,$4)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, t := range totals {
+		if _, err := stmt.Exec(jobID, t.StudentID, t.OldTotal, t.NewTotal); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PointRecalcRepository) GetStudentTotals(jobID string) ([]models.PointRecalcStudentTotal, error) {
+	query := `
+		SELECT job_id, student_id, old_total, new_total
+		FROM point_recalc_student_totals
+		WHERE job_id = $1
+		ORDER BY ABS(new_total - old_total) DESC, student_id
+	`
+
+	rows, err := r.DB.Query(query, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PointRecalcStudentTotal
+	for rows.Next() {
+		var t models.PointRecalcStudentTotal
+		if err := rows.Scan(
+			&t.JobID,
+			&t.StudentID,
+			&t.OldTotal,
+			&t.NewTotal,
+		); err != nil {
+			return nil, err
+		}
+		t.Delta = t.NewTotal - t.OldTotal
+		list = append(list, t)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type PointRuleRepository struct {
+	DB *sql.DB
+}
+
+func NewPointRuleRepository(db *sql.DB) *PointRuleRepository {
+	return &PointRuleRepository{DB: db}
+}
+
+// ================= CREATE (versi baru) =================
+
+func (r *PointRuleRepository) Create(set *models.PointRuleSet) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// kunci tabel supaya nomor versi tidak bentrok
+	if _, err := tx.Exec(`LOCK TABLE point_rule_sets IN EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) + 1 FROM point_rule_sets`).Scan(&set.Version); err != nil {
+		return err
+	}
+
+	set.ID = uuid.NewString()
+	set.IsActive = false
+	set.CreatedAt = time.Now()
+
+	_, err = tx.Exec(`
+		INSERT INTO point_rule_sets
+		(id, version, name, description, is_active, created_by, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`,
+		set.ID,
+		set.Version,
+		set.Name,
+		set.Description,
+		set.IsActive,
+		set.CreatedBy,
+		set.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO point_rules
+		(id, rule_set_id, priority, achievement_type, competition_level,
+		 rank, medal_type, tag, points, description)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+	`
+
+	for i := range set.Rules {
+		rule := &set.Rules[i]
+		rule.ID = uuid.NewString()
+		rule.RuleSetID = set.ID
+
+		if _, err := tx.Exec(
+			query,
+			rule.ID,
+			rule.RuleSetID,
+			rule.Priority,
+			rule.AchievementType,
+			rule.CompetitionLevel,
+			rule.Rank,
+			rule.MedalType,
+			rule.Tag,
+			rule.Points,
+			rule.Description,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ================= GET ALL (tanpa rules) =================
+
+func (r *PointRuleRepository) GetAll() ([]models.PointRuleSet, error) {
+	query := `
+		SELECT id, version, name, description, is_active, created_by, created_at, activated_at
+		FROM point_rule_sets
+		ORDER BY version DESC
+	`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PointRuleSet
+	for rows.Next() {
+		var set models.PointRuleSet
+		if err := rows.Scan(
+			&set.ID,
+			&set.Version,
+			&set.Name,
+			&set.Description,
+			&set.IsActive,
+			&set.CreatedBy,
+			&set.CreatedAt,
+			&set.ActivatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, set)
+	}
+
+	return list, nil
+}
+
+// ================= GET BY ID / ACTIVE (dengan rules) =================
+
+func (r *PointRuleRepository) GetByID(id string) (*models.PointRuleSet, error) {
+	return r.getOne(`WHERE id = $1`, id)
+}
+
+// GetActive mengembalikan sql.ErrNoRows jika belum ada rule set aktif
+func (r *PointRuleRepository) GetActive() (*models.PointRuleSet, error) {
+	return r.getOne(`WHERE is_active = TRUE`)
+}
+
+func (r *PointRuleRepository) getOne(where string, args ...interface{}) (*models.PointRuleSet, error) {
+	query := `
+		SELECT id, version, name, description, is_active, created_by, created_at, activated_at
+		FROM point_rule_sets
+		` + where + `
+		LIMIT 1
+	`
+
+	var set models.PointRuleSet
+	err := r.DB.QueryRow(query, args...).Scan(
+		&set.ID,
+		&set.Version,
+		&set.Name,
+		&set.Description,
+		&set.IsActive,
+		&set.CreatedBy,
+		&set.CreatedAt,
+		&set.ActivatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := r.getRules(set.ID)
+	if err != nil {
+		return nil, err
+	}
+	set.Rules = rules
+
+	return &set, nil
+}
+
+func (r *PointRuleRepository) getRules(ruleSetID string) ([]models.PointRule, error) {
+	query := `
+		SELECT id, rule_set_id, priority, achievement_type, competition_level,
+		       rank, medal_type, tag, points, description
+		FROM point_rules
+		WHERE rule_set_id = $1
+		ORDER BY priority, id
+	`
+
+	rows, err := r.DB.Query(query, ruleSetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PointRule
+	for rows.Next() {
+		var rule models.PointRule
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.RuleSetID,
+			&rule.Priority,
+			&rule.AchievementType,
+			&rule.CompetitionLevel,
+			&rule.Rank,
+			&rule.MedalType,
+			&rule.Tag,
+			&rule.Points,
+			&rule.Description,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, rule)
+	}
+
+	return list, nil
+}
+
+// ================= ACTIVATE =================
+// hanya satu rule set yang aktif dalam satu waktu
+
+func (r *PointRuleRepository) Activate(id string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE point_rule_sets SET is_active = FALSE WHERE is_active = TRUE`); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE point_rule_sets
+		SET is_active = TRUE,
+		    activated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// ================= DELETE =================
+// hanya rule set yang belum pernah diaktifkan (supaya audit tetap utuh)
+
+func (r *PointRuleRepository) Delete(id string) error {
+	res, err := r.DB.Exec(`
+		DELETE FROM point_rule_sets
+		WHERE id = $1
+		  AND activated_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type ReportScheduleRepository struct {
+	DB *sql.DB
+}
+
+func NewReportScheduleRepository(db *sql.DB) *ReportScheduleRepository {
+	return &ReportScheduleRepository{DB: db}
+}
+
+// row dari QueryRow maupun Rows
+type scannable interface {
+	Scan(dest ...interface{}) error
+}
+
+// ================= SCHEDULE =================
+
+const scheduleColumns = `
+	id, name, report_type, filters, period, format, cron, enabled,
+	created_by, created_at, updated_at, last_run_at
+`
+
+func scanSchedule(row scannable) (*models.ReportSchedule, error) {
+	var s models.ReportSchedule
+	var filters []byte
+	if err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.ReportType,
+		&filters,
+		&s.Period,
+		&s.Format,
+		&s.Cron,
+		&s.Enabled,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.LastRunAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filters, &s.Filters); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *ReportScheduleRepository) Create(s *models.ReportSchedule) error {
+	filters, err := json.Marshal(s.Filters)
+	if err != nil {
+		return err
+	}
+
+	s.ID = uuid.NewString()
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+
+	_, err = r.DB.Exec(`
+		INSERT INTO report_schedules
+		(id, name, report_type, filters, period, format, cron, enabled,
+		 created_by, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+	`,
+		s.ID,
+		s.Name,
+		s.ReportType,
+		filters,
+		s.Period,
+		s.Format,
+		s.Cron,
+		s.Enabled,
+		s.CreatedBy,
+		s.CreatedAt,
+		s.UpdatedAt,
+	)
+	return err
+}
+
+func (r *ReportScheduleRepository) Update(s *models.ReportSchedule) error {
+	filters, err := json.Marshal(s.Filters)
+	if err != nil {
+		return err
+	}
+
+	s.UpdatedAt = time.Now()
+
+	res, err := r.DB.Exec(`
+		UPDATE report_schedules
+		SET name = $2,
+		    report_type = $3,
+		    filters = $4,
+		    period = $5,
+		    format = $6,
+		    cron = $7,
+		    enabled = $8,
+		    updated_at = $9
+		WHERE id = $1
+	`,
+		s.ID,
+		s.Name,
+		s.ReportType,
+		filters,
+		s.Period,
+		s.Format,
+		s.Cron,
+		s.Enabled,
+		s.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete hanya menghapus jadwal; arsip hasilnya tetap ada (schedule_id = NULL)
+func (r *ReportScheduleRepository) Delete(id string) error {
+	res, err := r.DB.Exec(`DELETE FROM report_schedules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *ReportScheduleRepository) GetByID(id string) (*models.ReportSchedule, error) {
+	return scanSchedule(r.DB.QueryRow(`
+		SELECT `+scheduleColumns+`
+		FROM report_schedules
+		WHERE id = $1
+	`, id))
+}
+
+// GetAll: enabledOnly dipakai scheduler saat start
+func (r *ReportScheduleRepository) GetAll(enabledOnly bool) ([]models.ReportSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM report_schedules`
+	if enabledOnly {
+		query += ` WHERE enabled = TRUE`
+	}
+	query += ` ORDER BY created_at`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.ReportSchedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+
+	return list, rows.Err()
+}
+
+// ================= RUN (ARSIP) =================
+
+const runColumns = `
+	id, schedule_id, report_type, filters, format, trigger, status,
+	scheduled_for, storage_key, file_name, content_type, size, checksum,
+	error, created_by, created_at, started_at, finished_at
+`
+
+func scanRun(row scannable) (*models.ReportRun, error) {
+	var run models.ReportRun
+	var filters []byte
+	if err := row.Scan(
+		&run.ID,
+		&run.ScheduleID,
+		&run.ReportType,
+		&filters,
+		&run.Format,
+		&run.Trigger,
+		&run.Status,
+		&run.ScheduledFor,
+		&run.StorageKey,
+		&run.FileName,
+		&run.ContentType,
+		&run.Size,
+		&run.Checksum,
+		&run.Error,
+		&run.CreatedBy,
+		&run.CreatedAt,
+		&run.StartedAt,
+		&run.FinishedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filters, &run.Filters); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// CreateRun mencatat laporan baru (status pending). Untuk run terjadwal,
+// false berarti slot (schedule_id, scheduled_for) sudah diambil instance lain.
+func (r *ReportScheduleRepository) CreateRun(run *models.ReportRun) (bool, error) {
+	filters, err := json.Marshal(run.Filters)
+	if err != nil {
+		return false, err
+	}
+
+	run.ID = uuid.NewString()
+	run.Status = models.ReportRunPending
+	run.CreatedAt = time.Now()
+
+	res, err := r.DB.Exec(`
+		INSERT INTO report_runs
+		(id, schedule_id, report_type, filters, format, trigger, status,
+		 scheduled_for, created_by, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		ON CONFLICT (schedule_id, scheduled_for) DO NOTHING
+	`,
+		run.ID,
+		run.ScheduleID,
+		run.ReportType,
+		filters,
+		run.Format,
+		run.Trigger,
+		run.Status,
+		run.ScheduledFor,
+		run.CreatedBy,
+		run.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *ReportScheduleRepository) MarkRunning(id string) error {
+	_, err := r.DB.Exec(`
+		UPDATE report_runs
+		SET status = 'running', started_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkCompleted menyimpan metadata file yang sudah ada di storage
+func (r *ReportScheduleRepository) MarkCompleted(run *models.ReportRun) error {
+	_, err := r.DB.Exec(`
+		UPDATE report_runs
+		SET status = 'completed',
+		    storage_key = $2,
+		    file_name = $3,
+		    content_type = $4,
+		    size = $5,
+		    checksum = $6,
+		    finished_at = NOW()
+		WHERE id = $1
+	`,
+		run.ID,
+		run.StorageKey,
+		run.FileName,
+		run.ContentType,
+		run.Size,
+		run.Checksum,
+	)
+	if err != nil {
+		return err
+	}
+
+	if run.ScheduleID != nil {
+		_, err = r.DB.Exec(`
+			UPDATE report_schedules SET last_run_at = NOW() WHERE id = $1
+		`, *run.ScheduleID)
+	}
+	return err
+}
+
+func (r *ReportScheduleRepository) MarkFailed(id string, runErr error) error {
+	msg := runErr.Error()
+	_, err := r.DB.Exec(`
+		UPDATE report_runs
+		SET status = 'failed', error = $2, finished_at = NOW()
+		WHERE id = $1
+	`, id, msg)
+	return err
+}
+
+func (r *ReportScheduleRepository) GetRun(id string) (*models.ReportRun, error) {
+	return scanRun(r.DB.QueryRow(`
+		SELECT `+runColumns+`
+		FROM report_runs
+		WHERE id = $1
+	`, id))
+}
+
+// GetRuns: arsip terbaru dulu, dengan total untuk pagination
+func (r *ReportScheduleRepository) GetRuns(q models.ReportRunQuery, page models.Pagination) ([]models.ReportRun, int, error) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if q.ScheduleID != "" {
+		add("schedule_id = $%d", q.ScheduleID)
+	}
+	if q.ReportType != "" {
+		add("report_type = $%d", q.ReportType)
+	}
+	if q.Status != "" {
+		add("status = $%d", q.Status)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM report_runs`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, page.Limit, (page.Page-1)*page.Limit)
+	rows, err := r.DB.Query(
+		`SELECT `+runColumns+` FROM report_runs`+where+
+			fmt.Sprintf(` ORDER BY created_at DESC, id LIMIT $%d OFFSET $%d`, len(args)-1, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.ReportRun{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *run)
+	}
+
+	return list, total, rows.Err()
+}
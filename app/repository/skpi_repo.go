@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"pbluas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type SKPIRepository struct {
+	DB *sql.DB
+}
+
+func NewSKPIRepository(db *sql.DB) *SKPIRepository {
+	return &SKPIRepository{DB: db}
+}
+
+// GetOrCreate mengembalikan dokumen SKPI mahasiswa. Nomor dibuat sekali
+// dengan format <urut>/SKPI/<tahun terbit>, cetak ulang memakai nomor yang sama.
+func (r *SKPIRepository) GetOrCreate(studentID string, issuedBy string) (*models.SKPIDocument, error) {
+	doc, err := r.GetByStudentID(studentID)
+	if err == nil {
+		return doc, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	now := time.Now()
+	_, err = r.DB.Exec(`
+		INSERT INTO skpi_documents (id, student_id, number, issued_by, issued_at)
+		VALUES ($1, $2, LPAD(nextval('skpi_number_seq')::text, 5, '0') || '/SKPI/' || $3, $4, $5)
+		ON CONFLICT (student_id) DO NOTHING
+	`, uuid.NewString(), studentID, now.Format("2006"), issuedBy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByStudentID(studentID)
+}
+
+func (r *SKPIRepository) GetByStudentID(studentID string) (*models.SKPIDocument, error) {
+	var doc models.SKPIDocument
+	err := r.DB.QueryRow(`
+		SELECT id, student_id, number, issued_by, issued_at
+		FROM skpi_documents
+		WHERE student_id = $1
+	`, studentID).Scan(
+		&doc.ID,
+		&doc.StudentID,
+		&doc.Number,
+		&doc.IssuedBy,
+		&doc.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
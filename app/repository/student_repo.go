@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"pbluas/app/models"
+
+	"github.com/lib/pq"
 )
 
 type StudentRepository interface {
@@ -11,6 +13,8 @@ type StudentRepository interface {
 	GetStudentByID(id string) (*models.StudentDetail, error)
 	UpdateAdvisor(studentID string, lecturerID string) error
 	GetStudentByUserID(userID string) (*models.Student, error)
+	GetStudentsByIDs(ids []string) (map[string]models.StudentDetail, error)
+	CountByProgramStudy(academicYear, programStudy string) (map[string]int, error)
 }
 
 type studentRepository struct {
@@ -238,3 +242,71 @@ func (r *studentRepository) GetStudentByUserID(userID string) (*models.Student,
 }
 
 
+
+// GetStudentsByIDs: data banyak mahasiswa sekaligus, key = students.id
+func (r *studentRepository) GetStudentsByIDs(ids []string) (map[string]models.StudentDetail, error) {
+	result := make(map[string]models.StudentDetail)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT s.id, s.user_id, s.student_id, u.full_name, s.program_study, s.academic_year
+		FROM students s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = ANY($1::uuid[])
+	`
+
+	rows, err := r.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var st models.StudentDetail
+		if err := rows.Scan(
+			&st.ID,
+			&st.UserID,
+			&st.StudentID,
+			&st.FullName,
+			&st.ProgramStudy,
+			&st.AcademicYear,
+		); err != nil {
+			return nil, err
+		}
+		result[st.ID] = st
+	}
+
+	return result, rows.Err()
+}
+
+// CountByProgramStudy: jumlah mahasiswa per program studi,
+// bisa dibatasi satu angkatan / satu program studi (kosong = semua)
+func (r *studentRepository) CountByProgramStudy(academicYear, programStudy string) (map[string]int, error) {
+	query := `
+		SELECT program_study, COUNT(*)
+		FROM students
+		WHERE ($1 = '' OR academic_year = $1)
+		  AND ($2 = '' OR LOWER(program_study) = LOWER($2))
+		GROUP BY program_study
+	`
+
+	rows, err := r.DB.Query(query, academicYear, programStudy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var program string
+		var n int
+		if err := rows.Scan(&program, &n); err != nil {
+			return nil, err
+		}
+		result[program] = n
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+
+	"pbluas/app/models"
+	"pbluas/validation"
+)
+
+// masa studi maksimal (14 semester) untuk menentukan mahasiswa aktif
+const maxStudyYears = 7
+
+// academicYearStart: tahun awal tahun akademik yang sedang berjalan
+// (tahun akademik dimulai Agustus, sama dengan semester ganjil)
+func academicYearStart(now time.Time) int {
+	if now.Month() >= time.August {
+		return now.Year()
+	}
+	return now.Year() - 1
+}
+
+// cohortYear: tahun angkatan dari academic_year ("2023" atau "2023/2024")
+func cohortYear(academicYear string) (int, bool) {
+	s := strings.TrimSpace(academicYear)
+	if len(s) < 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(s[:4])
+	return year, err == nil
+}
+
+func accreditationLevel(level string) string {
+	switch level {
+	case "local", "regional":
+		return "local_regional"
+	case "national", "international":
+		return level
+	default:
+		return "unknown"
+	}
+}
+
+func addLevel(l *models.AccreditationLevels, level string) {
+	switch accreditationLevel(level) {
+	case "local_regional":
+		l.LocalRegional++
+	case "national":
+		l.National++
+	case "international":
+		l.International++
+	default:
+		l.Unknown++
+	}
+	l.Total++
+}
+
+// GetAccreditation godoc
+// @Summary Accreditation and IKU indicators
+// @Description Verified achievement counts per level (local/regional, national, international) for the TS-2, TS-1 and TS academic years, and the IKU share of active students with national or international achievements. Academic years run August to July; active students are cohorts within 7 years. Requires report:view.
+// @Tags Reports
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param ts query int false "Start year of the TS academic year (default: current academic year)"
+// @Param program_study query string false "Program study (default: all)"
+// @Param date_field query string false "Date used to place an achievement in a year: event (default) or verified"
+// @Param format query string false "Output format: json (default) or xlsx"
+// @Success 200 {object} models.AccreditationReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/accreditation [get]
+func (s *ReportService) GetAccreditation(c *fiber.Ctx) error {
+	format, err := exportFormat(c)
+	if err != nil {
+		return exportFormatError(c, err)
+	}
+	if format == FormatCSV {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "format must be one of: json xlsx",
+		})
+	}
+
+	var q models.AccreditationQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid query parameters",
+		})
+	}
+	if errs := validation.Struct(&q); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	report, err := s.buildAccreditation(c.Context(), q)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if format == FormatXLSX {
+		f, err := accreditationWorkbook(report)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		defer f.Close()
+
+		buf, err := f.WriteToBuffer()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+
+		name := fmt.Sprintf("akreditasi-TS%d", report.TS)
+		if report.ProgramStudy != "" {
+			name += "-" + unsafeFileChars.ReplaceAllString(report.ProgramStudy, "_")
+		}
+		c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+name+`.xlsx"`)
+		return c.Send(buf.Bytes())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    report,
+	})
+}
+
+func (s *ReportService) buildAccreditation(ctx context.Context, q models.AccreditationQuery) (*models.AccreditationReport, error) {
+	if q.TS == 0 {
+		q.TS = academicYearStart(time.Now())
+	}
+	if q.DateField == "" {
+		q.DateField = models.StatisticsDateEvent
+	}
+
+	report := &models.AccreditationReport{
+		TS:           q.TS,
+		ProgramStudy: q.ProgramStudy,
+		DateField:    q.DateField,
+	}
+
+	// 1️⃣ jendela TS-2, TS-1, TS (Agustus s/d Juli)
+	for _, offset := range []int{2, 1, 0} {
+		year := q.TS - offset
+		label := "TS"
+		if offset > 0 {
+			label = fmt.Sprintf("TS-%d", offset)
+		}
+		report.Windows = append(report.Windows, models.AccreditationWindow{
+			Label:        label,
+			AcademicYear: fmt.Sprintf("%d/%d", year, year+1),
+			Start:        time.Date(year, time.August, 1, 0, 0, 0, 0, time.Local),
+			End:          time.Date(year+1, time.August, 1, 0, 0, 0, 0, time.Local),
+		})
+	}
+	first, last := report.Windows[0], report.Windows[len(report.Windows)-1]
+
+	// 2️⃣ penerima poin achievement verified di prodi ini
+	filter := models.StatisticsFilter{
+		ProgramStudy: q.ProgramStudy,
+		DateField:    q.DateField,
+		From:         first.Start.Format("2006-01-02"),
+		To:           last.End.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+	holders, err := s.RefRepo.GetVerifiedPointHolders(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var mongoIDs []string
+	for _, h := range holders {
+		if !seen[h.MongoID] {
+			seen[h.MongoID] = true
+			mongoIDs = append(mongoIDs, h.MongoID)
+		}
+	}
+
+	facts, err := s.AchievementRepo.FindLevelFacts(ctx, mongoIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Achievement, len(facts))
+	for _, a := range facts {
+		byID[a.ID.Hex()] = a
+	}
+
+	// windowOf: indeks jendela sebuah achievement, -1 jika di luar TS-2..TS
+	windowOf := func(h models.PointHolder, a models.Achievement) int {
+		for i, w := range report.Windows {
+			if q.DateField == models.StatisticsDateVerified {
+				if h.VerifiedAt != nil && !h.VerifiedAt.Before(w.Start) && h.VerifiedAt.Before(w.End) {
+					return i
+				}
+				continue
+			}
+			date := models.EventDate(a.Details)
+			if date != "" && date >= w.Start.Format("2006-01-02") && date < w.End.Format("2006-01-02") {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// 3️⃣ jumlah prestasi per tingkat (achievement beregu dihitung sekali)
+	achieving := make([]map[string]bool, len(report.Windows))
+	for i := range achieving {
+		achieving[i] = map[string]bool{}
+	}
+	counted := map[string]bool{}
+
+	for _, h := range holders {
+		a, ok := byID[h.MongoID]
+		if !ok {
+			continue
+		}
+		i := windowOf(h, a)
+		if i < 0 {
+			continue
+		}
+
+		level := a.Details.CompetitionLevel
+		if !counted[h.MongoID] {
+			counted[h.MongoID] = true
+			addLevel(&report.Windows[i].Levels, level)
+			addLevel(&report.Total, level)
+		}
+
+		if level == "national" || level == "international" {
+			achieving[i][h.StudentID] = true
+		}
+	}
+
+	// 4️⃣ IKU: mahasiswa aktif (angkatan <= tahun, masa studi <= 7 tahun)
+	students, err := s.StudentRepo.GetAllStudents()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report.Windows {
+		w := &report.Windows[i]
+		year := w.Start.Year()
+
+		for _, st := range students {
+			if q.ProgramStudy != "" && !strings.EqualFold(st.ProgramStudy, q.ProgramStudy) {
+				continue
+			}
+			cohort, ok := cohortYear(st.AcademicYear)
+			if !ok || cohort > year || year-cohort >= maxStudyYears {
+				continue
+			}
+
+			w.IKU.ActiveStudents++
+			if achieving[i][st.ID] {
+				w.IKU.AchievingStudents++
+			}
+		}
+
+		if w.IKU.ActiveStudents > 0 {
+			w.IKU.Percentage = round2(float64(w.IKU.AchievingStudents) * 100 / float64(w.IKU.ActiveStudents))
+		}
+	}
+
+	return report, nil
+}
+
+// accreditationWorkbook: layout mengikuti tabel LKPS (prestasi mahasiswa)
+// dan lembar IKU 2
+func accreditationWorkbook(r *models.AccreditationReport) (*excelize.File, error) {
+	f := excelize.NewFile()
+
+	const lkps = "Prestasi Mahasiswa"
+	const iku = "IKU 2"
+	if err := f.SetSheetName("Sheet1", lkps); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(iku); err != nil {
+		return nil, err
+	}
+
+	border := []excelize.Border{
+		{Type: "left", Color: "000000", Style: 1},
+		{Type: "right", Color: "000000", Style: 1},
+		{Type: "top", Color: "000000", Style: 1},
+		{Type: "bottom", Color: "000000", Style: 1},
+	}
+	header, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9E1F2"}},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center", WrapText: true},
+		Border:    border,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cell, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Border:    border,
+	})
+	if err != nil {
+		return nil, err
+	}
+	total, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Border:    border,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	program := r.ProgramStudy
+	if program == "" {
+		program = "Semua program studi"
+	}
+
+	// ===== LKPS: Prestasi Mahasiswa =====
+	set := func(sheet, axis string, value interface{}) {
+		_ = f.SetCellValue(sheet, axis, value)
+	}
+
+	set(lkps, "A1", "Tabel Prestasi Mahasiswa")
+	set(lkps, "A2", "Program Studi: "+program)
+	set(lkps, "A3", fmt.Sprintf("TS = %d/%d", r.TS, r.TS+1))
+
+	set(lkps, "A5", "No")
+	set(lkps, "B5", "Tahun Akademik")
+	set(lkps, "C5", "TS")
+	set(lkps, "D5", "Jumlah Prestasi per Tingkat")
+	set(lkps, "D6", "Lokal/Wilayah")
+	set(lkps, "E6", "Nasional")
+	set(lkps, "F6", "Internasional")
+	set(lkps, "G5", "Jumlah")
+	for _, m := range [][2]string{{"A5", "A6"}, {"B5", "B6"}, {"C5", "C6"}, {"D5", "F5"}, {"G5", "G6"}} {
+		_ = f.MergeCell(lkps, m[0], m[1])
+	}
+	_ = f.SetCellStyle(lkps, "A5", "G6", header)
+
+	row := 7
+	for i, w := range r.Windows {
+		lv := w.Levels
+		_ = f.SetSheetRow(lkps, fmt.Sprintf("A%d", row), &[]interface{}{
+			i + 1, w.AcademicYear, w.Label,
+			lv.LocalRegional, lv.National, lv.International,
+			lv.LocalRegional + lv.National + lv.International,
+		})
+		_ = f.SetCellStyle(lkps, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), cell)
+		row++
+	}
+	_ = f.SetSheetRow(lkps, fmt.Sprintf("A%d", row), &[]interface{}{
+		"Jumlah", "", "",
+		r.Total.LocalRegional, r.Total.National, r.Total.International,
+		r.Total.LocalRegional + r.Total.National + r.Total.International,
+	})
+	_ = f.MergeCell(lkps, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row))
+	_ = f.SetCellStyle(lkps, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), total)
+
+	if r.Total.Unknown > 0 {
+		set(lkps, fmt.Sprintf("A%d", row+2), fmt.Sprintf(
+			"Catatan: %d prestasi tanpa tingkat tidak dimasukkan ke tabel.", r.Total.Unknown))
+	}
+
+	_ = f.SetColWidth(lkps, "A", "A", 6)
+	_ = f.SetColWidth(lkps, "B", "B", 16)
+	_ = f.SetColWidth(lkps, "C", "C", 8)
+	_ = f.SetColWidth(lkps, "D", "G", 15)
+
+	// ===== IKU 2 =====
+	set(iku, "A1", "IKU 2 - Mahasiswa Berprestasi Tingkat Nasional/Internasional")
+	set(iku, "A2", "Program Studi: "+program)
+
+	_ = f.SetSheetRow(iku, "A4", &[]interface{}{
+		"No", "Tahun Akademik", "TS",
+		"Jumlah Mahasiswa Aktif",
+		"Mahasiswa Berprestasi Nasional/Internasional",
+		"Persentase (%)",
+	})
+	_ = f.SetCellStyle(iku, "A4", "F4", header)
+
+	row = 5
+	for i, w := range r.Windows {
+		_ = f.SetSheetRow(iku, fmt.Sprintf("A%d", row), &[]interface{}{
+			i + 1, w.AcademicYear, w.Label,
+			w.IKU.ActiveStudents, w.IKU.AchievingStudents, w.IKU.Percentage,
+		})
+		_ = f.SetCellStyle(iku, fmt.Sprintf("A%d", row), fmt.Sprintf("F%d", row), cell)
+		row++
+	}
+
+	_ = f.SetColWidth(iku, "A", "A", 6)
+	_ = f.SetColWidth(iku, "B", "B", 16)
+	_ = f.SetColWidth(iku, "C", "C", 8)
+	_ = f.SetColWidth(iku, "D", "F", 22)
+	_ = f.SetRowHeight(iku, 4, 32)
+
+	return f, nil
+}
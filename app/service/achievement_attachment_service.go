@@ -0,0 +1,701 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+	"pbluas/storage"
+	"pbluas/validation"
+)
+
+// ================= ATTACHMENT DOWNLOAD =================
+
+// AttachmentView adalah attachment yang dikirim ke client. FileURL lama
+// ("/uploads/...") tidak pernah dilayani server, jadi diganti dengan URL
+// endpoint download yang memeriksa hak akses.
+type AttachmentView struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"fileName"`
+	FileType    string    `json:"fileType"`
+	Size        int64     `json:"size,omitempty"`
+	SHA256      string    `json:"sha256,omitempty"`
+	ScanStatus  string    `json:"scanStatus"`
+	DownloadURL string    `json:"downloadUrl"`
+	PreviewURL  string    `json:"previewUrl,omitempty"` // thumbnail, hanya gambar
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
+
+func attachmentViews(a *models.Achievement) []AttachmentView {
+	views := make([]AttachmentView, 0, len(a.Attachments))
+	for _, att := range a.Attachments {
+		preview := ""
+		if att.ThumbnailKey != "" {
+			preview = attachmentDownloadPath(a.ID.Hex(), att.AttachmentID()) + "/preview"
+		}
+
+		views = append(views, AttachmentView{
+			ID:          att.AttachmentID(),
+			FileName:    att.FileName,
+			FileType:    att.FileType,
+			Size:        att.Size,
+			SHA256:      att.SHA256,
+			ScanStatus:  att.ScanState(),
+			DownloadURL: attachmentDownloadPath(a.ID.Hex(), att.AttachmentID()),
+			PreviewURL:  preview,
+			UploadedAt:  att.UploadedAt,
+		})
+	}
+	return views
+}
+
+func attachmentDownloadPath(achievementID, attachmentID string) string {
+	return "/api/v1/achievements/" + achievementID + "/attachments/" + attachmentID
+}
+
+func signedAttachmentPath(achievementID, attachmentID string) string {
+	return "/api/v1/files/attachments/" + achievementID + "/" + attachmentID
+}
+
+func findAttachment(a *models.Achievement, attachmentID string) (*models.AchievementAttachment, bool) {
+	for i := range a.Attachments {
+		if a.Attachments[i].AttachmentID() == attachmentID {
+			return &a.Attachments[i], true
+		}
+	}
+	return nil, false
+}
+
+// authorizedAttachment memuat attachment setelah memeriksa hak akses
+// user (aturan yang sama dengan Detail). Jika gagal, mengembalikan
+// status HTTP beserta error-nya.
+func (s *AchievementService) authorizedAttachment(c *fiber.Ctx) (*models.Achievement, *models.AchievementAttachment, int, error) {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	achievementID := c.Params("id")
+
+	ref, err := s.ReferenceRepo.GetByMongoID(achievementID)
+	if err != nil {
+		return nil, nil, 404, errors.New("achievement not found")
+	}
+
+	if !s.canView(role, userID, ref) {
+		return nil, nil, 403, errors.New("forbidden")
+	}
+
+	achievement, att, err := s.loadAttachment(achievementID, c.Params("attachmentId"))
+	if err != nil {
+		return nil, nil, 404, err
+	}
+
+	// hanya pemilik achievement yang bisa mengunggah attachment
+	isOwner := false
+	if role == "Mahasiswa" {
+		student, err := s.StudentRepo.GetStudentByUserID(userID)
+		isOwner = err == nil && student.ID == ref.StudentID
+	}
+
+	if err := canOpenAttachment(isOwner, att); err != nil {
+		return nil, nil, 403, err
+	}
+
+	return achievement, att, 200, nil
+}
+
+func (s *AchievementService) loadAttachment(achievementID, attachmentID string) (*models.Achievement, *models.AchievementAttachment, error) {
+	achievement, err := s.AchievementRepo.FindByID(context.Background(), achievementID)
+	if err != nil {
+		return nil, nil, errors.New("achievement detail not found")
+	}
+
+	att, ok := findAttachment(achievement, attachmentID)
+	if !ok {
+		return nil, nil, errors.New("attachment not found")
+	}
+
+	return achievement, att, nil
+}
+
+// streamAttachment mengalirkan isi file dari storage ke response
+func (s *AchievementService) streamAttachment(c *fiber.Ctx, att *models.AchievementAttachment) error {
+	r, info, err := s.Storage.Get(c.Context(), att.Key())
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "attachment file not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	contentType := att.FileType
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	setHeaders := func() {
+		c.Set(fiber.HeaderContentType, contentType)
+		c.Set(fiber.HeaderContentDisposition, "attachment; filename="+strconv.Quote(att.FileName))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+	}
+
+	// attachment lama belum punya hash, langsung dialirkan
+	if att.SHA256 == "" {
+		setHeaders()
+		// fasthttp menutup reader setelah selesai dikirim
+		return c.SendStream(r, int(info.Size))
+	}
+
+	defer r.Close()
+
+	data, err := readVerified(r, info.Size, att.SHA256)
+	if err != nil {
+		log.Println("attachment", att.AttachmentID(), "download failed:", err)
+		return c.Status(500).JSON(fiber.Map{
+			"message": errIntegrity.Error(),
+		})
+	}
+
+	setHeaders()
+	c.Set(fiber.HeaderETag, `"`+att.SHA256+`"`)
+	return c.Send(data)
+}
+
+// DownloadAttachment godoc
+// @Summary Download achievement attachment
+// @Description Stream an attachment file. Same access rules as achievement detail (owner/team member, advisor, admin). Verifiers can only open files that passed the malware scan; infected files are quarantined.
+// @Tags Achievements
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	_, att, status, err := s.authorizedAttachment(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return s.streamAttachment(c, att)
+}
+
+// AttachmentPreview godoc
+// @Summary Get attachment thumbnail
+// @Description JPEG thumbnail of an image attachment. Same access rules as the download endpoint.
+// @Tags Achievements
+// @Produce jpeg
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId}/preview [get]
+func (s *AchievementService) AttachmentPreview(c *fiber.Ctx) error {
+	_, att, status, err := s.authorizedAttachment(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if att.ThumbnailKey == "" {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "preview not available",
+		})
+	}
+
+	r, info, err := s.Storage.Get(c.Context(), att.ThumbnailKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "preview not available",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	return c.SendStream(r, int(info.Size))
+}
+
+// AttachmentLink godoc
+// @Summary Create signed attachment link
+// @Description Create a time-limited HMAC-signed download URL that works without a token (for reports and emails)
+// @Tags Achievements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param expires_in query int false "Validity in minutes (default 15, max 10080)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId}/link [get]
+func (s *AchievementService) AttachmentLink(c *fiber.Ctx) error {
+	achievement, att, status, err := s.authorizedAttachment(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	ttl := defaultSignedURLTTL
+	if minutes := c.QueryInt("expires_in"); minutes > 0 {
+		ttl = time.Duration(minutes) * time.Minute
+	}
+	if ttl > maxSignedURLTTL {
+		ttl = maxSignedURLTTL
+	}
+
+	url, expiresAt, err := SignURL(signedAttachmentPath(achievement.ID.Hex(), att.AttachmentID()), ttl)
+	if err != nil {
+		return c.Status(503).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"url":       url,
+			"expiresAt": expiresAt,
+		},
+	})
+}
+
+// SignedAttachmentDownload godoc
+// @Summary Download attachment via signed link
+// @Description Public endpoint, access is granted by the expires & signature query parameters
+// @Tags Files
+// @Produce octet-stream
+// @Param id path string true "Achievement ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param expires query int true "Unix expiry time"
+// @Param signature query string true "HMAC signature"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /files/attachments/{id}/{attachmentId} [get]
+func (s *AchievementService) SignedAttachmentDownload(c *fiber.Ctx) error {
+	achievementID := c.Params("id")
+	attachmentID := c.Params("attachmentId")
+
+	path := signedAttachmentPath(achievementID, attachmentID)
+	if !VerifySignedURL(path, c.Query("expires"), c.Query("signature")) {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "invalid or expired link",
+		})
+	}
+
+	_, att, err := s.loadAttachment(achievementID, attachmentID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	// link publik hanya untuk file yang sudah clean
+	if err := canOpenAttachment(false, att); err != nil {
+		return c.Status(403).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return s.streamAttachment(c, att)
+}
+
+// ================= ATTACHMENT MANAGEMENT =================
+
+// editableAchievement: attachment hanya boleh diubah pemilik achievement
+// (Mahasiswa) selama status masih draft.
+func (s *AchievementService) editableAchievement(c *fiber.Ctx) (*models.Achievement, int, error) {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	if role != "Mahasiswa" {
+		return nil, 403, errors.New("only mahasiswa can manage attachments")
+	}
+
+	achievementID := c.Params("id")
+
+	ref, err := s.ReferenceRepo.GetByMongoID(achievementID)
+	if err != nil {
+		return nil, 404, errors.New("achievement not found")
+	}
+
+	student, err := s.StudentRepo.GetStudentByUserID(userID)
+	if err != nil || student.ID != ref.StudentID {
+		return nil, 403, errors.New("forbidden")
+	}
+
+	if ref.Status != "draft" {
+		return nil, 403, errors.New("only draft achievement can change attachments")
+	}
+
+	achievement, err := s.AchievementRepo.FindByID(context.Background(), achievementID)
+	if err != nil {
+		return nil, 404, errors.New("achievement detail not found")
+	}
+
+	return achievement, 200, nil
+}
+
+// storeAttachmentFile memeriksa file terhadap upload policy lalu menyimpannya
+// ke storage dengan key dari server. existing dipakai untuk batas total ukuran.
+func (s *AchievementService) storeAttachmentFile(
+	c *fiber.Ctx,
+	achievement *models.Achievement,
+	file *multipart.FileHeader,
+	attachmentID string,
+	existing []models.AchievementAttachment,
+) (*models.AchievementAttachment, int, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, 400, errors.New("failed to read file")
+	}
+	defer src.Close()
+
+	// Content-Type dari client diabaikan, jenis file ditentukan dari isinya
+	checked, err := attachmentUploadPolicy().checkUpload(file, src, existing)
+	if err != nil {
+		return nil, 400, err
+	}
+
+	achievementID := achievement.ID.Hex()
+
+	// nama object selalu baru, termasuk saat mengganti file
+	key := "achievements/" + achievementID + "/" + uuid.NewString() + checked.Extension
+
+	// hash dihitung sambil file dikirim ke storage
+	hash := sha256.New()
+	if err := s.Storage.Put(c.Context(), key, io.TeeReader(checked.Reader, hash), checked.Size, checked.ContentType); err != nil {
+		return nil, 500, errors.New("failed to save file")
+	}
+
+	return &models.AchievementAttachment{
+		ID:         attachmentID,
+		FileName:   sanitizeFileName(file.Filename, checked.Extension),
+		FileURL:    attachmentDownloadPath(achievementID, attachmentID),
+		FileType:   checked.ContentType,
+		Size:       checked.Size,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		StorageKey: key,
+		UploadedAt: time.Now(),
+		ScanStatus: models.ScanPending,
+	}, 200, nil
+}
+
+// removeStoredFile menghapus file dari storage. Gagal hapus tidak
+// membatalkan request, cukup dicatat di log (file yatim bisa dibersihkan nanti).
+func (s *AchievementService) removeStoredFile(ctx context.Context, key string) {
+	if err := s.Storage.Delete(ctx, key); err != nil {
+		log.Println("failed to delete attachment file", key+":", err)
+	}
+}
+
+func (s *AchievementService) recordAttachmentEvent(mongoID, attachmentID, action, fileName, actorID string) {
+	event := &models.AttachmentEvent{
+		MongoID:      mongoID,
+		AttachmentID: attachmentID,
+		Action:       action,
+		FileName:     fileName,
+		ActorID:      &actorID,
+	}
+	if err := s.AttachmentEvents.Create(event); err != nil {
+		log.Println("failed to record attachment event:", err)
+	}
+}
+
+func saveAttachmentsError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrAttachmentsChanged) {
+		return c.Status(409).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.Status(500).JSON(fiber.Map{
+		"message": err.Error(),
+	})
+}
+
+// ListAttachments godoc
+// @Summary List achievement attachments
+// @Description List attachments in display order. Same access rules as achievement detail.
+// @Tags Achievements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments [get]
+func (s *AchievementService) ListAttachments(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	achievementID := c.Params("id")
+
+	ref, err := s.ReferenceRepo.GetByMongoID(achievementID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement not found",
+		})
+	}
+
+	if !s.canView(role, userID, ref) {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "forbidden",
+		})
+	}
+
+	achievement, err := s.AchievementRepo.FindByID(context.Background(), achievementID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement detail not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    attachmentViews(achievement),
+	})
+}
+
+// DeleteAttachment godoc
+// @Summary Delete achievement attachment
+// @Description Remove an attachment from a draft achievement and delete its file (owner only)
+// @Tags Achievements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [delete]
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	achievement, status, err := s.editableAchievement(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	removed, ok := findAttachment(achievement, c.Params("attachmentId"))
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "attachment not found",
+		})
+	}
+	removedAtt := *removed
+
+	var remaining []models.AchievementAttachment
+	for _, att := range achievement.Attachments {
+		if att.AttachmentID() != removedAtt.AttachmentID() {
+			remaining = append(remaining, att)
+		}
+	}
+
+	if err := s.AchievementRepo.SetAttachments(context.Background(), achievement.ID.Hex(), achievement.UpdatedAt, remaining); err != nil {
+		return saveAttachmentsError(c, err)
+	}
+
+	s.removeStoredFile(c.Context(), removedAtt.Key())
+	if removedAtt.ThumbnailKey != "" {
+		s.removeStoredFile(c.Context(), removedAtt.ThumbnailKey)
+	}
+	s.recordAttachmentEvent(achievement.ID.Hex(), removedAtt.AttachmentID(), models.AttachmentDeleted, removedAtt.FileName, userID)
+
+	return c.JSON(fiber.Map{
+		"message": "attachment deleted successfully",
+	})
+}
+
+// ReplaceAttachment godoc
+// @Summary Replace achievement attachment
+// @Description Upload a new file for an existing attachment, keeping its ID and position. The old file is deleted (owner only, draft only).
+// @Tags Achievements
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Param file formData file true "Attachment file"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [put]
+func (s *AchievementService) ReplaceAttachment(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	achievement, status, err := s.editableAchievement(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	old, ok := findAttachment(achievement, c.Params("attachmentId"))
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "attachment not found",
+		})
+	}
+	oldAtt := *old
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "file is required",
+		})
+	}
+
+	// file lama tidak dihitung dalam batas total ukuran
+	var others []models.AchievementAttachment
+	for _, att := range achievement.Attachments {
+		if att.AttachmentID() != oldAtt.AttachmentID() {
+			others = append(others, att)
+		}
+	}
+
+	replacement, status, err := s.storeAttachmentFile(c, achievement, file, oldAtt.AttachmentID(), others)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	attachments := make([]models.AchievementAttachment, len(achievement.Attachments))
+	copy(attachments, achievement.Attachments)
+	for i := range attachments {
+		if attachments[i].AttachmentID() == oldAtt.AttachmentID() {
+			attachments[i] = *replacement
+		}
+	}
+
+	if err := s.AchievementRepo.SetAttachments(context.Background(), achievement.ID.Hex(), achievement.UpdatedAt, attachments); err != nil {
+		s.removeStoredFile(c.Context(), replacement.Key())
+		return saveAttachmentsError(c, err)
+	}
+
+	s.removeStoredFile(c.Context(), oldAtt.Key())
+	if oldAtt.ThumbnailKey != "" {
+		s.removeStoredFile(c.Context(), oldAtt.ThumbnailKey)
+	}
+	s.recordAttachmentEvent(achievement.ID.Hex(), replacement.ID, models.AttachmentReplaced, replacement.FileName, userID)
+	s.scanAttachmentAsync(achievement.ID.Hex(), *replacement)
+
+	return c.JSON(fiber.Map{
+		"message":      "attachment replaced successfully",
+		"attachmentId": replacement.ID,
+		"scanStatus":   replacement.ScanStatus,
+	})
+}
+
+// ReorderAttachments godoc
+// @Summary Reorder achievement attachments
+// @Description Set the display order of attachments. The order must list every attachment ID exactly once (owner only, draft only).
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param body body models.AttachmentOrderRequest true "New order"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/order [put]
+func (s *AchievementService) ReorderAttachments(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	var req models.AttachmentOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	achievement, status, err := s.editableAchievement(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	byID := make(map[string]models.AchievementAttachment)
+	for _, att := range achievement.Attachments {
+		byID[att.AttachmentID()] = att
+	}
+
+	if len(req.Order) != len(byID) {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "order must contain every attachment exactly once",
+		})
+	}
+
+	var ordered []models.AchievementAttachment
+	for _, id := range req.Order {
+		att, ok := byID[id]
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{
+				"message": "order must contain every attachment exactly once",
+			})
+		}
+		ordered = append(ordered, att)
+		delete(byID, id)
+	}
+
+	if err := s.AchievementRepo.SetAttachments(context.Background(), achievement.ID.Hex(), achievement.UpdatedAt, ordered); err != nil {
+		return saveAttachmentsError(c, err)
+	}
+
+	s.recordAttachmentEvent(achievement.ID.Hex(), "", models.AttachmentReordered, "", userID)
+
+	return c.JSON(fiber.Map{
+		"message": "attachments reordered successfully",
+	})
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"pbluas/app/models"
+)
+
+// ================= DUPLICATE DETECTION =================
+
+// bobot setiap field saat membandingkan dua achievement
+var duplicateFieldWeights = map[string]float64{
+	"competitionName": 0.30,
+	"title":           0.20,
+	"eventDate":       0.20,
+	"organizer":       0.15,
+	"rank":            0.15,
+}
+
+const (
+	duplicateScoreThreshold = 0.75 // skor minimal dianggap kemungkinan duplikat
+	fieldMatchThreshold     = 0.85 // kemiripan teks minimal dianggap field sama
+)
+
+// findDuplicates membandingkan achievement dengan achievement lain yang
+// masih aktif (reference tidak deleted) dan mengembalikan yang mirip.
+func (s *AchievementService) findDuplicates(ctx context.Context, a *models.Achievement) ([]models.DuplicateFlag, error) {
+	candidates, err := s.AchievementRepo.FindDuplicateCandidates(ctx, a)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	var ids []string
+	for _, c := range candidates {
+		ids = append(ids, c.ID.Hex())
+	}
+
+	refs, err := s.ReferenceRepo.GetByMongoIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]bool)
+	for _, r := range refs {
+		active[r.MongoID] = true
+	}
+
+	flags := []models.DuplicateFlag{}
+	for _, c := range candidates {
+		if !active[c.ID.Hex()] {
+			continue
+		}
+
+		score, matched := duplicateScore(a, &c)
+		sameRank := c.StudentID != a.StudentID && isSameRankClaim(a, &c)
+
+		if score < duplicateScoreThreshold && !sameRank {
+			continue
+		}
+
+		flags = append(flags, models.DuplicateFlag{
+			AchievementID: c.ID.Hex(),
+			StudentID:     c.StudentID,
+			Title:         c.Title,
+			Score:         math.Round(score*100) / 100,
+			MatchedFields: matched,
+			SameRankClaim: sameRank,
+		})
+	}
+
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Score > flags[j].Score
+	})
+
+	return flags, nil
+}
+
+// duplicateScore menghasilkan skor 0..1. Field yang kosong di salah satu
+// achievement tidak ikut dihitung supaya data yang tidak lengkap tidak
+// menurunkan skor.
+func duplicateScore(a, b *models.Achievement) (float64, []string) {
+	total := 0.0
+	weight := 0.0
+	var matched []string
+
+	compare := func(field string, sim float64, ok bool) {
+		if !ok {
+			return
+		}
+		w := duplicateFieldWeights[field]
+		weight += w
+		total += w * sim
+		if sim >= fieldMatchThreshold {
+			matched = append(matched, field)
+		}
+	}
+
+	compare("competitionName", textSimilarity(a.Details.CompetitionName, b.Details.CompetitionName),
+		a.Details.CompetitionName != "" && b.Details.CompetitionName != "")
+	compare("title", textSimilarity(a.Title, b.Title),
+		a.Title != "" && b.Title != "")
+	compare("eventDate", exactSimilarity(a.Details.EventDate, b.Details.EventDate),
+		a.Details.EventDate != "" && b.Details.EventDate != "")
+	compare("organizer", textSimilarity(a.Details.Organizer, b.Details.Organizer),
+		a.Details.Organizer != "" && b.Details.Organizer != "")
+	compare("rank", rankSimilarity(a.Details.Rank, b.Details.Rank),
+		a.Details.Rank != nil && b.Details.Rank != nil)
+
+	// butuh minimal dua field untuk dibandingkan
+	if len(matched) < 2 || weight == 0 {
+		return 0, matched
+	}
+
+	return total / weight, matched
+}
+
+// isSameRankClaim: kompetisi + tanggal + peringkat sama persis.
+// Dua student berbeda tidak mungkin sama-sama juara 1 individu.
+func isSameRankClaim(a, b *models.Achievement) bool {
+	if a.Details.Rank == nil || b.Details.Rank == nil {
+		return false
+	}
+	if a.Details.EventDate == "" || a.Details.EventDate != b.Details.EventDate {
+		return false
+	}
+
+	return *a.Details.Rank == *b.Details.Rank &&
+		textSimilarity(a.Details.CompetitionName, b.Details.CompetitionName) >= fieldMatchThreshold
+}
+
+func exactSimilarity(a, b string) float64 {
+	if strings.TrimSpace(a) == strings.TrimSpace(b) {
+		return 1
+	}
+	return 0
+}
+
+func rankSimilarity(a, b *float64) float64 {
+	if *a == *b {
+		return 1
+	}
+	return 0
+}
+
+// textSimilarity = 1 - (levenshtein / panjang maksimum) setelah normalisasi
+func textSimilarity(a, b string) float64 {
+	ra := []rune(normalizeText(a))
+	rb := []rune(normalizeText(b))
+
+	if len(ra) == 0 && len(rb) == 0 {
+		return 0
+	}
+
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+// normalizeText: huruf kecil, buang tanda baca, rapikan spasi
+func normalizeText(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
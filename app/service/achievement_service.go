@@ -2,38 +2,59 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"fmt"
-	"os"
-	"path"
+	"log"
+	"sort"
+	"strings"
 	"time"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"pbluas/app/models"
 	"pbluas/app/repository"
+	"pbluas/scanner"
+	"pbluas/storage"
+	"pbluas/validation"
 )
 
 type AchievementService struct {
 	AchievementRepo *repository.AchievementRepository
 	ReferenceRepo   *repository.AchievementReferenceRepository
 	StudentRepo     repository.StudentRepository 
+	MemberRepo      *repository.AchievementMemberRepository
+	PointRules      *PointRuleService
+	Storage         storage.Storage
+	AttachmentEvents *repository.AttachmentEventRepository
+	Scanner         scanner.Scanner
 }
 
 func NewAchievementService(
 	ar *repository.AchievementRepository,
 	rr *repository.AchievementReferenceRepository,
 	sr repository.StudentRepository,
-
+	mr *repository.AchievementMemberRepository,
+	pr *PointRuleService,
+	st storage.Storage,
+	ae *repository.AttachmentEventRepository,
+	sc scanner.Scanner,
 	) *AchievementService {
 	return &AchievementService{
 		AchievementRepo: ar,
 		ReferenceRepo:   rr,
 		StudentRepo:     sr,
+		MemberRepo:      mr,
+		PointRules:      pr,
+		Storage:         st,
+		AttachmentEvents: ae,
+		Scanner:         sc,
 	}
 }
 
 // ===== BUSINESS LOGIC (TIDAK DIUBAH) =====
-func (s *AchievementService) Create(ctx context.Context, studentID string, req *models.Achievement) error {
+// members boleh kosong (achievement individu). Reference dan anggota tim
+// disimpan dalam satu transaksi; jika gagal, dokumen Mongo dihapus lagi.
+func (s *AchievementService) Create(ctx context.Context, studentID string, req *models.Achievement, members []models.AchievementMember) error {
 	if studentID == "" {
 		return errors.New("invalid student id")
 	}
@@ -44,13 +65,20 @@ func (s *AchievementService) Create(ctx context.Context, studentID string, req *
 		return err
 	}
 
-	// Simpan reference ke Postgres
+	// Simpan reference (+ anggota tim) ke Postgres
 	ref := &models.AchievementReference{
 		StudentID: studentID,
 		MongoID:   req.ID.Hex(),
 	}
 
-	return s.ReferenceRepo.Create(ref)
+	if err := s.ReferenceRepo.CreateWithMembers(ref, members); err != nil {
+		if derr := s.AchievementRepo.DeleteByID(ctx, req.ID); derr != nil {
+			log.Println("achievement", req.ID.Hex(), "rollback failed:", derr)
+		}
+		return err
+	}
+
+	return nil
 }
 
 // CreateAchievement godoc
@@ -64,6 +92,7 @@ func (s *AchievementService) Create(ctx context.Context, studentID string, req *
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /achievements [post]
 func (s *AchievementService) CreateHandler(c *fiber.Ctx) error {
 	claims := c.Locals("user_claims").(jwt.MapClaims)
@@ -77,6 +106,10 @@ func (s *AchievementService) CreateHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	if errs := validation.Struct(&reqBody); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
 	var studentID string
 
 	// ================= ROLE HANDLING =================
@@ -106,8 +139,34 @@ func (s *AchievementService) CreateHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// ================= TYPE-SPECIFIC DETAILS =================
+	prepareAchievementDetails(&reqBody)
+
+	// ================= TEAM MEMBERS =================
+	members, err := s.buildTeamMembers(studentID, reqBody.Members, nil)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	pointRule := ""
+	if len(members) > 0 {
+		pointRule, err = resolvePointRule(reqBody.PointRule)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+	}
+
 	// ================= CREATE ACHIEVEMENT =================
-	points := calculateAchievementPoints(reqBody)
+	evaluation, err := s.PointRules.Calculate(reqBody.AchievementType, reqBody.Details, reqBody.Tags)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 
 	achievement := &models.Achievement{
 		AchievementType: reqBody.AchievementType,
@@ -115,28 +174,75 @@ func (s *AchievementService) CreateHandler(c *fiber.Ctx) error {
 		Description:     reqBody.Description,
 		Details:         reqBody.Details,
 		Tags:            reqBody.Tags,
-		Points:          points,
+		Points:          evaluation.Points,
+		PointRuleVersion: evaluation.RuleSetVersion,
+		PointRule:       pointRule,
 	}
 
-	if err := s.Create(context.Background(), studentID, achievement); err != nil {
+	// ================= DUPLICATE CHECK =================
+	// hanya peringatan, tidak memblokir pembuatan achievement
+	achievement.StudentID = studentID
+	duplicates, err := s.findDuplicates(context.Background(), achievement)
+	if err != nil {
+		log.Println("duplicate check for new achievement of student", studentID, "failed:", err)
+	}
+	achievement.DuplicateFlags = duplicates
+
+	if err := s.Create(context.Background(), studentID, achievement, members); err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"message": err.Error(),
 		})
 	}
 
-	return c.Status(201).JSON(fiber.Map{
+	response := fiber.Map{
 		"message": "achievement created successfully",
-	})
+		"id":      achievement.ID.Hex(),
+	}
+	if len(duplicates) > 0 {
+		response["warnings"] = fiber.Map{
+			"message":    "possible duplicate achievements found",
+			"duplicates": duplicates,
+		}
+	}
+
+	return c.Status(201).JSON(response)
 }
 
 
+// prepareAchievementDetails membuang field details milik jenis lain.
+// Jenis dan field wajib sudah dicek oleh validation.Struct.
+func prepareAchievementDetails(req *models.AchievementCreateRequest) {
+	if spec := models.GetAchievementTypeSpec(req.AchievementType); spec != nil {
+		req.Details = spec.Normalize(req.Details)
+	}
+}
+
+// ListAchievementTypes godoc
+// @Summary List achievement types
+// @Description List supported achievement types with their required and optional detail fields
+// @Tags Achievements
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /achievements/types [get]
+func (s *AchievementService) ListTypes(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    models.AchievementTypes,
+	})
+}
+
 // ListAchievements godoc
 // @Summary Get achievements list
 // @Description List achievements based on user role (Mahasiswa, Dosen Wali, Admin)
 // @Tags Achievements
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Security BearerAuth
+// @Param format query string false "Output format: json (default), csv, xlsx"
 // @Success 200 {array} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Router /achievements [get]
 func (s *AchievementService) ListByRole(c *fiber.Ctx) error {
@@ -144,8 +250,12 @@ func (s *AchievementService) ListByRole(c *fiber.Ctx) error {
 	role := claims["role"].(string)
 	userID := claims["id"].(string)
 
+	format, err := exportFormat(c)
+	if err != nil {
+		return exportFormatError(c, err)
+	}
+
 	var refs []models.AchievementReference
-	var err error
 
 	switch role {
 case "Mahasiswa":
@@ -193,10 +303,25 @@ default:
 		})
 	}
 
+	membersMap, err := s.MemberRepo.GetByMongoIDs(mongoIDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	if format != FormatJSON {
+		sort.Slice(achievements, func(i, j int) bool {
+			return achievements[i].CreatedAt.After(achievements[j].CreatedAt)
+		})
+		return sendExport(c, format, "prestasi", achievementListColumns,
+			achievementListRows(achievements, statusMap, membersMap, role != "Mahasiswa"))
+	}
+
 	// gabungkan status
 	var response []fiber.Map
 	for _, a := range achievements {
-		response = append(response, fiber.Map{
+		item := fiber.Map{
 			"id":              a.ID.Hex(),
 			"studentId":       a.StudentID,
 			"achievementType": a.AchievementType,
@@ -204,9 +329,20 @@ default:
 			"description":     a.Description,
 			"details":         a.Details,
 			"tags":            a.Tags,
+			"members":         membersMap[a.ID.Hex()],
+			"pointRule":       a.PointRule,
 			"status":          statusMap[a.ID.Hex()],
+			"attachments":     attachmentViews(&a),
 			"createdAt":       a.CreatedAt,
-		})
+		}
+
+		// flag duplikat hanya untuk verifikator
+		if role != "Mahasiswa" {
+			item["possibleDuplicate"] = len(a.DuplicateFlags) > 0
+			item["duplicateFlags"] = a.DuplicateFlags
+		}
+
+		response = append(response, item)
 	}
 
 	return c.JSON(response)
@@ -244,28 +380,7 @@ func (s *AchievementService) Detail(c *fiber.Ctx) error {
 	}
 
 	// 2️⃣ RBAC CHECK
-	switch role {
-
-	case "Mahasiswa":
-		student, err := s.StudentRepo.GetStudentByUserID(userID)
-		if err != nil || student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{
-				"message": "forbidden",
-			})
-		}
-
-	case "Dosen", "Dosen Wali", "Lecturer":
-		allowed, err := s.ReferenceRepo.IsAdvisorOfStudent(userID, ref.StudentID)
-		if err != nil || !allowed {
-			return c.Status(403).JSON(fiber.Map{
-				"message": "forbidden",
-			})
-		}
-
-	case "Admin":
-		// bebas
-
-	default:
+	if !s.canView(role, userID, ref) {
 		return c.Status(403).JSON(fiber.Map{
 			"message": "forbidden",
 		})
@@ -279,8 +394,15 @@ func (s *AchievementService) Detail(c *fiber.Ctx) error {
 		})
 	}
 
+	members, err := s.MemberRepo.GetByMongoID(achievementID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
 	// 4️⃣ response gabungan
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"id":              achievement.ID.Hex(),
 		"studentId":       achievement.StudentID,
 		"achievementType": achievement.AchievementType,
@@ -288,13 +410,56 @@ func (s *AchievementService) Detail(c *fiber.Ctx) error {
 		"description":     achievement.Description,
 		"details":         achievement.Details,
 		"tags":            achievement.Tags,
+		"members":         members,
+		"pointRule":       achievement.PointRule,
 		"status":          ref.Status,
 		"submittedAt":     ref.SubmittedAt,
 		"verifiedAt":      ref.VerifiedAt,
 		"verifiedBy":      ref.VerifiedBy,
 		"rejectionNote":   ref.RejectionNote,
+		"revokedAt":       ref.RevokedAt,
+		"revokedBy":       ref.RevokedBy,
+		"revocationReason": ref.RevocationReason,
+		"attachments":     attachmentViews(achievement),
 		"createdAt":       achievement.CreatedAt,
-	})
+	}
+
+	// flag duplikat & file bukti yang dipakai ulang hanya untuk verifikator
+	if role != "Mahasiswa" {
+		response["duplicateFlags"] = achievement.DuplicateFlags
+
+		reuse, err := s.findEvidenceReuse(context.Background(), achievement)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		response["evidenceReuse"] = reuse
+		response["evidenceReused"] = len(reuse) > 0
+	}
+
+	return c.JSON(response)
+}
+
+// canView: aturan akses detail achievement.
+// Mahasiswa = pemilik / anggota tim, dosen = dosen wali pemilik / anggota tim,
+// admin = semua.
+func (s *AchievementService) canView(role, userID string, ref *models.AchievementReference) bool {
+	switch role {
+	case "Mahasiswa":
+		student, err := s.StudentRepo.GetStudentByUserID(userID)
+		return err == nil && s.isOwnerOrMember(student.ID, ref)
+
+	case "Dosen", "Dosen Wali", "Lecturer":
+		allowed, err := s.ReferenceRepo.IsAdvisorOfAchievement(userID, ref.MongoID)
+		return err == nil && allowed
+
+	case "Admin":
+		return true
+
+	default:
+		return false
+	}
 }
 
 // UpdateAchievement godoc
@@ -308,6 +473,7 @@ func (s *AchievementService) Detail(c *fiber.Ctx) error {
 // @Param body body models.AchievementCreateRequest true "Update payload"
 // @Success 200 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /achievements/{id} [put]
 func (s *AchievementService) Update(c *fiber.Ctx) error {
 	// 🔐 ambil claims
@@ -355,13 +521,65 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 		})
 	}
 
-	points := calculateAchievementPoints(req)
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	prepareAchievementDetails(&req)
+
+	current, err := s.AchievementRepo.FindByID(context.Background(), achievementID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement detail not found",
+		})
+	}
+
+	// anggota tim hanya diganti jika field members dikirim;
+	// dicek dulu sebelum ada data yang ditulis
+	var members []models.AchievementMember
+	if req.Members != nil {
+		previous, err := s.MemberRepo.GetByMongoID(achievementID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+
+		members, err = s.buildTeamMembers(ref.StudentID, req.Members, previous)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+
+		if len(members) > 0 {
+			req.PointRule, err = resolvePointRule(req.PointRule)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"message": err.Error(),
+				})
+			}
+		} else {
+			req.PointRule = ""
+		}
+	} else {
+		req.PointRule = current.PointRule
+	}
+
+	evaluation, err := s.PointRules.Calculate(req.AchievementType, req.Details, req.Tags)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
 	// 5️⃣ update MongoDB
 	err = s.AchievementRepo.UpdateByID(
 		context.Background(),
 		achievementID,
 		req,
-		points,
+		evaluation.Points,
+		evaluation.RuleSetVersion,
 	)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -369,14 +587,45 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 		})
 	}
 
+	// 6️⃣ ganti anggota tim; jika gagal, dokumen Mongo dikembalikan
+	if req.Members != nil {
+		if err := s.MemberRepo.ReplaceMembers(achievementID, members); err != nil {
+			s.restoreAchievement(current)
+			return c.Status(500).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "achievement updated successfully",
 	})
 }
 
+// restoreAchievement mengembalikan field yang diubah Update
+func (s *AchievementService) restoreAchievement(previous *models.Achievement) {
+	err := s.AchievementRepo.UpdateByID(
+		context.Background(),
+		previous.ID.Hex(),
+		models.AchievementCreateRequest{
+			AchievementType: previous.AchievementType,
+			Title:           previous.Title,
+			Description:     previous.Description,
+			Details:         previous.Details,
+			Tags:            previous.Tags,
+			PointRule:       previous.PointRule,
+		},
+		previous.Points,
+		previous.PointRuleVersion,
+	)
+	if err != nil {
+		log.Println("achievement", previous.ID.Hex(), "restore failed:", err)
+	}
+}
+
 // UploadAchievementAttachment godoc
 // @Summary Upload achievement attachment
-// @Description Upload file for achievement
+// @Description Upload evidence file for a draft achievement. Only PDF, JPEG and PNG (checked by content), limited in size per file and per achievement.
 // @Tags Achievements
 // @Accept multipart/form-data
 // @Produce json
@@ -385,44 +634,21 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
 // @Param file formData file true "Attachment file"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Router /achievements/{id}/attachments [post]
 func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 	claims := c.Locals("user_claims").(jwt.MapClaims)
-	role := claims["role"].(string)
 	userID := claims["id"].(string)
 
-	if role != "Mahasiswa" {
-		return c.Status(403).JSON(fiber.Map{
-			"message": "only mahasiswa can upload attachment",
-		})
-	}
-
-	achievementID := c.Params("id")
-
-	// 1️⃣ ambil reference
-	ref, err := s.ReferenceRepo.GetByMongoID(achievementID)
+	// 1️⃣ owner + status draft
+	achievement, status, err := s.editableAchievement(c)
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{
-			"message": "achievement not found",
-		})
-	}
-
-	// 2️⃣ cek owner
-	student, err := s.StudentRepo.GetStudentByUserID(userID)
-	if err != nil || student.ID != ref.StudentID {
-		return c.Status(403).JSON(fiber.Map{
-			"message": "forbidden",
-		})
-	}
-
-	// 3️⃣ cek status
-	if ref.Status != "draft" {
-		return c.Status(403).JSON(fiber.Map{
-			"message": "only draft achievement can upload attachment",
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
 		})
 	}
 
-	// 4️⃣ ambil file
+	// 2️⃣ ambil file
 	file, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -430,40 +656,34 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 		})
 	}
 
-	// 5️⃣ simpan file
-	uploadDir := "./uploads/achievements"
-	_ = os.MkdirAll(uploadDir, os.ModePerm)
-
-	filename := fmt.Sprintf("%s_%s", achievementID, file.Filename)
-	filePath := path.Join(uploadDir, filename)
-
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "failed to save file",
+	// 3️⃣ cek & simpan file ke storage
+	attachment, status, err := s.storeAttachmentFile(c, achievement, file, uuid.NewString(), achievement.Attachments)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
 		})
 	}
 
-	// 6️⃣ simpan metadata ke Mongo
-	attachment := models.AchievementAttachment{
-		FileName:   file.Filename,
-		FileURL:    "/uploads/achievements/" + filename,
-		FileType:   file.Header.Get("Content-Type"),
-		UploadedAt: time.Now(),
-	}
-
+	// 4️⃣ simpan metadata ke Mongo (409 jika ada upload / perubahan lain
+	// sejak dokumen dibaca, batas total ukuran dicek dari dokumen itu)
 	err = s.AchievementRepo.AddAttachment(
 		context.Background(),
-		achievementID,
-		attachment,
+		achievement.ID.Hex(),
+		achievement.UpdatedAt,
+		*attachment,
 	)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		s.removeStoredFile(c.Context(), attachment.Key())
+		return saveAttachmentsError(c, err)
 	}
 
+	s.recordAttachmentEvent(achievement.ID.Hex(), attachment.ID, models.AttachmentUploaded, attachment.FileName, userID)
+	s.scanAttachmentAsync(achievement.ID.Hex(), *attachment)
+
 	return c.JSON(fiber.Map{
-		"message": "attachment uploaded successfully",
+		"message":      "attachment uploaded successfully",
+		"attachmentId": attachment.ID,
+		"scanStatus":   attachment.ScanStatus,
 	})
 }
 
@@ -592,6 +812,37 @@ func (s *AchievementService) Submit(c *fiber.Ctx) error {
 		}
 	}
 
+	// ================= TEAM CONFIRMATION =================
+	unconfirmed, err := s.MemberRepo.CountUnconfirmed(mongoID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if unconfirmed > 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "all team members must confirm participation before submit",
+			"unconfirmed": unconfirmed,
+		})
+	}
+
+	// ================= DUPLICATE CHECK =================
+	// dihitung ulang karena isi achievement bisa berubah sejak dibuat
+	achievement, err := s.AchievementRepo.FindByID(context.Background(), mongoID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement detail not found",
+		})
+	}
+
+	// hanya peringatan: gagal cek duplikat tidak memblokir submit, tapi dicatat
+	duplicates, err := s.findDuplicates(context.Background(), achievement)
+	if err != nil {
+		log.Println("duplicate check for achievement", mongoID, "failed:", err)
+	} else if err := s.AchievementRepo.SetDuplicateFlags(context.Background(), mongoID, duplicates); err != nil {
+		log.Println("saving duplicate flags for achievement", mongoID, "failed:", err)
+	}
+
 	// ================= SUBMIT =================
 	// pakai method Submit(id) yang kamu tambahkan di repo
 	if err := s.ReferenceRepo.Submit(ref.ID); err != nil {
@@ -600,9 +851,17 @@ func (s *AchievementService) Submit(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"message": "achievement submitted for verification",
-	})
+	}
+	if len(duplicates) > 0 {
+		response["warnings"] = fiber.Map{
+			"message":    "possible duplicate achievements found",
+			"duplicates": duplicates,
+		}
+	}
+
+	return c.JSON(response)
 }
 
 // VerifyAchievement godoc
@@ -646,10 +905,10 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 
 	// ================= DOSEN WALI CHECK =================
 	if role != "Admin" {
-		allowed, err := s.ReferenceRepo.IsAdvisorOfStudent(userID, ref.StudentID)
+		allowed, err := s.ReferenceRepo.IsAdvisorOfAchievement(userID, ref.MongoID)
 		if err != nil || !allowed {
 			return c.Status(403).JSON(fiber.Map{
-				"message": "you are not advisor of this student",
+				"message": "you are not advisor of any member of this achievement",
 			})
 		}
 	}
@@ -677,6 +936,7 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 // @Param body body map[string]string true "Rejection note"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /achievements/{id}/reject [post]
 func (s *AchievementService) Reject(c *fiber.Ctx) error {
 	claims := c.Locals("user_claims").(jwt.MapClaims)
@@ -693,15 +953,19 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 
 	// ================= BODY =================
 	var body struct {
-		Note string `json:"note"`
+		Note string `json:"note" validate:"notblank,max=1000"`
 	}
 
-	if err := c.BodyParser(&body); err != nil || body.Note == "" {
+	if err := c.BodyParser(&body); err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"message": "rejection note is required",
+			"message": "invalid request body",
 		})
 	}
 
+	if errs := validation.Struct(&body); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
 	// ================= GET REFERENCE =================
 	ref, err := s.ReferenceRepo.GetByMongoID(mongoID)
 	if err != nil {
@@ -719,10 +983,10 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 
 	// ================= DOSEN WALI CHECK =================
 	if role != "Admin" {
-		allowed, err := s.ReferenceRepo.IsAdvisorOfStudent(userID, ref.StudentID)
+		allowed, err := s.ReferenceRepo.IsAdvisorOfAchievement(userID, ref.MongoID)
 		if err != nil || !allowed {
 			return c.Status(403).JSON(fiber.Map{
-				"message": "you are not advisor of this student",
+				"message": "you are not advisor of any member of this achievement",
 			})
 		}
 	}
@@ -739,9 +1003,85 @@ func (s *AchievementService) Reject(c *fiber.Ctx) error {
 	})
 }
 
+// RevokeAchievement godoc
+// @Summary Revoke verified achievement
+// @Description Revoke a verified achievement with mandatory reason (Admin only). Revoked achievements no longer count for points.
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Param body body map[string]string true "Revocation reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /achievements/{id}/revoke [post]
+func (s *AchievementService) Revoke(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+	mongoID := c.Params("id")
+
+	// ================= PERMISSION =================
+	if role != "Admin" {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "only admin can revoke achievement",
+		})
+	}
+
+	// ================= BODY =================
+	var body struct {
+		Reason string `json:"reason" validate:"notblank,max=1000"`
+	}
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+
+	if errs := validation.Struct(&body); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	// ================= GET REFERENCE =================
+	ref, err := s.ReferenceRepo.GetByMongoID(mongoID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement not found",
+		})
+	}
+
+	// ================= STATUS CHECK =================
+	if ref.Status != "verified" {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "only verified achievement can be revoked",
+		})
+	}
+
+	// ================= REVOKE =================
+	if err := s.ReferenceRepo.Revoke(ref.ID, userID, strings.TrimSpace(body.Reason)); err != nil {
+		// status berubah sejak dibaca (mis. sudah dicabut admin lain)
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(409).JSON(fiber.Map{
+				"message": "achievement is no longer verified",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "achievement revoked successfully",
+	})
+}
+
 // AchievementHistory godoc
 // @Summary Get achievement history
-// @Description Get status history of achievement
+// @Description Get status history of achievement, including attachment uploads, deletions, replacements and reordering
 // @Tags Achievements
 // @Produce json
 // @Security BearerAuth
@@ -773,14 +1113,14 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
 
 	case "Mahasiswa":
 		student, err := s.StudentRepo.GetStudentByUserID(userID)
-		if err != nil || student.ID != ref.StudentID {
+		if err != nil || !s.isOwnerOrMember(student.ID, ref) {
 			return c.Status(403).JSON(fiber.Map{
 				"message": "forbidden",
 			})
 		}
 
 	case "Dosen", "Dosen Wali", "Lecturer":
-		ok, err := s.ReferenceRepo.IsAdvisorOfStudent(userID, ref.StudentID)
+		ok, err := s.ReferenceRepo.IsAdvisorOfAchievement(userID, ref.MongoID)
 		if err != nil || !ok {
 			return c.Status(403).JSON(fiber.Map{
 				"message": "forbidden",
@@ -811,7 +1151,8 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
 		})
 	}
 
-	if ref.Status == "verified" && ref.VerifiedAt != nil {
+	// achievement yang dicabut tetap pernah verified
+	if (ref.Status == "verified" || ref.Status == "revoked") && ref.VerifiedAt != nil {
 		history = append(history, fiber.Map{
 			"status":      "verified",
 			"at":          *ref.VerifiedAt,
@@ -819,6 +1160,15 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
 		})
 	}
 
+	if ref.Status == "revoked" && ref.RevokedAt != nil {
+		history = append(history, fiber.Map{
+			"status":     "revoked",
+			"at":         *ref.RevokedAt,
+			"revoked_by": ref.RevokedBy,
+			"reason":     ref.RevocationReason,
+		})
+	}
+
 	if ref.Status == "rejected" && ref.VerifiedAt != nil {
 		history = append(history, fiber.Map{
 			"status": "rejected",
@@ -834,72 +1184,30 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"achievement_id": mongoID,
-		"history":        history,
-	})
-}
-
-// ================= POINT CALCULATION =================
-
-func calculateAchievementPoints(req models.AchievementCreateRequest) int {
-
-	// ================= COMPETITION =================
-	if req.AchievementType == "competition" {
-		level := req.Details.CompetitionLevel
-		rank := 0
-
-		if req.Details.Rank != nil {
-			rank = int(*req.Details.Rank)
-		}
-
-		switch level {
-
-		case "international":
-			switch rank {
-			case 1:
-				return 100
-			case 2:
-				return 80
-			case 3:
-				return 60
-			default:
-				return 40
-			}
-
-		case "national":
-			switch rank {
-			case 1:
-				return 80
-			case 2:
-				return 60
-			case 3:
-				return 40
-			default:
-				return 20
-			}
-
-		case "regional":
-			return 10
-
-		case "local":
-			return 5
-
-		default:
-			return 10
-		}
+	// ================= ATTACHMENT CHANGES =================
+	events, err := s.AttachmentEvents.GetByMongoID(mongoID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
 	}
 
-	// ================= PUBLICATION =================
-	if req.AchievementType == "publication" {
-		return 40
+	for _, e := range events {
+		history = append(history, fiber.Map{
+			"action":        "attachment_" + e.Action,
+			"at":            e.CreatedAt,
+			"attachment_id": e.AttachmentID,
+			"file_name":     e.FileName,
+			"by":            e.ActorID,
+		})
 	}
 
-	// ================= CERTIFICATION =================
-	if req.AchievementType == "certification" {
-		return 20
-	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i]["at"].(time.Time).Before(history[j]["at"].(time.Time))
+	})
 
-	// ================= DEFAULT =================
-	return 10
+	return c.JSON(fiber.Map{
+		"achievement_id": mongoID,
+		"history":        history,
+	})
 }
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pbluas/app/models"
+)
+
+// ================= TEAM ACHIEVEMENT =================
+
+// buildTeamMembers memvalidasi daftar anggota dari request.
+// Pemilik achievement (ownerID) selalu ikut sebagai anggota dan otomatis
+// terkonfirmasi; jika tidak ada ketua di request, pemilik menjadi ketua.
+// previous dipakai saat update supaya konfirmasi anggota lama tidak hilang.
+func (s *AchievementService) buildTeamMembers(
+	ownerID string,
+	reqs []models.AchievementMemberRequest,
+	previous []models.AchievementMember,
+) ([]models.AchievementMember, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	confirmed := make(map[string]*time.Time)
+	for _, p := range previous {
+		confirmed[p.StudentID] = p.ConfirmedAt
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	leaders := 0
+	var members []models.AchievementMember
+
+	for _, r := range reqs {
+		if r.StudentID == "" {
+			return nil, errors.New("member studentId is required")
+		}
+		if seen[r.StudentID] {
+			return nil, errors.New("duplicate member " + r.StudentID)
+		}
+		seen[r.StudentID] = true
+
+		role := r.Role
+		if role == "" {
+			role = models.MemberRoleMember
+		}
+		if role != models.MemberRoleLeader && role != models.MemberRoleMember {
+			return nil, errors.New("member role must be ketua or anggota")
+		}
+		if role == models.MemberRoleLeader {
+			leaders++
+		}
+
+		if r.StudentID != ownerID {
+			if _, err := s.StudentRepo.GetStudentByID(r.StudentID); err != nil {
+				return nil, errors.New("member student not found: " + r.StudentID)
+			}
+		}
+
+		members = append(members, models.AchievementMember{
+			StudentID:   r.StudentID,
+			Role:        role,
+			ConfirmedAt: confirmed[r.StudentID],
+		})
+	}
+
+	if !seen[ownerID] {
+		role := models.MemberRoleMember
+		if leaders == 0 {
+			role = models.MemberRoleLeader
+			leaders++
+		}
+		members = append(members, models.AchievementMember{
+			StudentID: ownerID,
+			Role:      role,
+		})
+	}
+
+	if leaders != 1 {
+		return nil, errors.New("team achievement must have exactly one ketua")
+	}
+
+	if len(members) < 2 {
+		return nil, errors.New("team achievement needs at least two members")
+	}
+
+	// pemilik yang mengajukan dianggap sudah konfirmasi
+	for i := range members {
+		if members[i].StudentID == ownerID && members[i].ConfirmedAt == nil {
+			members[i].ConfirmedAt = &now
+		}
+	}
+
+	return members, nil
+}
+
+// resolvePointRule menentukan aturan poin achievement beregu.
+// Default diambil dari env TEAM_POINT_RULE (duplicate jika kosong).
+func resolvePointRule(rule string) (string, error) {
+	if rule == "" {
+		rule = os.Getenv("TEAM_POINT_RULE")
+	}
+	if rule == "" {
+		rule = models.PointRuleDuplicate
+	}
+
+	if rule != models.PointRuleDuplicate && rule != models.PointRuleSplit {
+		return "", errors.New("pointRule must be duplicate or split")
+	}
+
+	return rule, nil
+}
+
+// memberPoints menghitung poin yang diterima satu anggota.
+// Achievement individu (memberCount == 0) mendapat poin penuh.
+func memberPoints(points int, rule string, memberCount int) int {
+	if memberCount <= 1 || rule != models.PointRuleSplit {
+		return points
+	}
+
+	// pembulatan ke bawah supaya total tidak melebihi poin achievement
+	return points / memberCount
+}
+
+// isOwnerOrMember: mahasiswa boleh mengakses achievement miliknya sendiri
+// atau achievement beregu di mana ia terdaftar sebagai anggota
+func (s *AchievementService) isOwnerOrMember(studentID string, ref *models.AchievementReference) bool {
+	if studentID == ref.StudentID {
+		return true
+	}
+
+	ok, err := s.MemberRepo.IsMember(ref.MongoID, studentID)
+	return err == nil && ok
+}
+
+// ConfirmMembership godoc
+// @Summary Confirm team membership
+// @Description Team member confirms participation in a team achievement (Mahasiswa only)
+// @Tags Achievements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /achievements/{id}/members/confirm [post]
+func (s *AchievementService) ConfirmMembership(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	if role != "Mahasiswa" {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "only mahasiswa can confirm membership",
+		})
+	}
+
+	mongoID := c.Params("id")
+
+	// 1️⃣ ambil reference
+	ref, err := s.ReferenceRepo.GetByMongoID(mongoID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement not found",
+		})
+	}
+
+	// 2️⃣ cek anggota
+	student, err := s.StudentRepo.GetStudentByUserID(userID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "student profile not found",
+		})
+	}
+
+	isMember, err := s.MemberRepo.IsMember(mongoID, student.ID)
+	if err != nil || !isMember {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "you are not a member of this achievement",
+		})
+	}
+
+	// 3️⃣ cek status
+	if ref.Status != "draft" {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "only draft achievement can be confirmed",
+		})
+	}
+
+	// 4️⃣ konfirmasi
+	if err := s.MemberRepo.Confirm(mongoID, student.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(400).JSON(fiber.Map{
+				"message": "participation already confirmed",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "participation confirmed",
+	})
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"pbluas/app/models"
+)
+
+// ================= CONTENT HASH =================
+
+// errIntegrity: isi file di storage tidak sama dengan hash saat upload
+var errIntegrity = errors.New("attachment integrity check failed")
+
+// readVerified membaca seluruh isi file dan mencocokkan SHA-256-nya.
+// File dibatasi upload policy (beberapa MB) sehingga aman dibaca ke memori.
+func readVerified(r io.Reader, size int64, expected string) ([]byte, error) {
+	var buf bytes.Buffer
+	if size > 0 {
+		buf.Grow(int(size))
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(&buf, io.TeeReader(r, h)); err != nil {
+		return nil, err
+	}
+
+	if hex.EncodeToString(h.Sum(nil)) != expected {
+		return nil, errIntegrity
+	}
+	return buf.Bytes(), nil
+}
+
+// findEvidenceReuse mencari attachment achievement ini yang file-nya
+// (berdasarkan SHA-256) juga dipakai di achievement lain, milik student
+// yang sama maupun student lain. Achievement yang sudah dihapus diabaikan.
+func (s *AchievementService) findEvidenceReuse(ctx context.Context, a *models.Achievement) ([]models.EvidenceReuse, error) {
+	byHash := make(map[string][]models.AchievementAttachment)
+	var hashes []string
+	for _, att := range a.Attachments {
+		if att.SHA256 == "" {
+			continue
+		}
+		if _, ok := byHash[att.SHA256]; !ok {
+			hashes = append(hashes, att.SHA256)
+		}
+		byHash[att.SHA256] = append(byHash[att.SHA256], att)
+	}
+
+	others, err := s.AchievementRepo.FindBySHA256(ctx, hashes, a.ID)
+	if err != nil || len(others) == 0 {
+		return nil, err
+	}
+
+	var ids []string
+	for _, o := range others {
+		ids = append(ids, o.ID.Hex())
+	}
+	refs, err := s.ReferenceRepo.GetByMongoIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool)
+	for _, ref := range refs {
+		active[ref.MongoID] = true
+	}
+
+	matches := make(map[string][]models.EvidenceReuseMatch)
+	for _, o := range others {
+		if !active[o.ID.Hex()] {
+			continue
+		}
+		for _, att := range o.Attachments {
+			if _, ok := byHash[att.SHA256]; !ok || att.SHA256 == "" {
+				continue
+			}
+			matches[att.SHA256] = append(matches[att.SHA256], models.EvidenceReuseMatch{
+				AchievementID: o.ID.Hex(),
+				StudentID:     o.StudentID,
+				Title:         o.Title,
+				AttachmentID:  att.AttachmentID(),
+				FileName:      att.FileName,
+				SameStudent:   o.StudentID == a.StudentID,
+			})
+		}
+	}
+
+	var result []models.EvidenceReuse
+	for _, hash := range hashes {
+		if len(matches[hash]) == 0 {
+			continue
+		}
+		for _, att := range byHash[hash] {
+			result = append(result, models.EvidenceReuse{
+				AttachmentID: att.AttachmentID(),
+				FileName:     att.FileName,
+				SHA256:       hash,
+				Matches:      matches[hash],
+			})
+		}
+	}
+
+	return result, nil
+}
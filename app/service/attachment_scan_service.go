@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"pbluas/app/models"
+	"pbluas/storage"
+)
+
+// ================= MALWARE SCAN =================
+// Attachment baru berstatus pending, lalu discan di background.
+// File infected dipindah ke prefix quarantine/ dan tidak bisa diunduh
+// siapa pun; verifikator hanya bisa membuka file yang clean.
+
+const (
+	quarantinePrefix = "quarantine/"
+	scanTimeout      = 5 * time.Minute
+)
+
+// scanAttachmentAsync dipanggil setelah metadata attachment tersimpan
+func (s *AchievementService) scanAttachmentAsync(achievementID string, att models.AchievementAttachment) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), scanTimeout)
+		defer cancel()
+
+		status, err := s.ScanAttachment(ctx, achievementID, att)
+		if err != nil {
+			// tetap pending, bisa diulang lewat: go run . scan
+			log.Println("scan attachment", att.AttachmentID(), "failed:", err)
+			return
+		}
+
+		if status == models.ScanClean {
+			s.thumbnailAfterScan(ctx, achievementID, att)
+		}
+	}()
+}
+
+// ScanAttachment men-scan satu attachment dan menyimpan hasilnya.
+// Mengembalikan status akhir (clean / infected).
+func (s *AchievementService) ScanAttachment(ctx context.Context, achievementID string, att models.AchievementAttachment) (string, error) {
+	r, _, err := s.Storage.Get(ctx, att.Key())
+	if err != nil {
+		return "", err
+	}
+	result, err := s.Scanner.Scan(ctx, r)
+	r.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if result.Clean {
+		return models.ScanClean, s.AchievementRepo.SetAttachmentScan(ctx, achievementID, att, models.ScanClean, "", "")
+	}
+
+	// karantina: pindahkan file supaya tidak ikut tersaji dari key lama
+	quarantineKey := quarantinePrefix + att.Key()
+	if err := moveObject(ctx, s.Storage, att.Key(), quarantineKey); err != nil {
+		log.Println("quarantine", att.Key(), "failed:", err)
+		quarantineKey = ""
+	}
+
+	log.Printf("attachment %s on achievement %s infected: %s", att.AttachmentID(), achievementID, result.Signature)
+
+	return models.ScanInfected, s.AchievementRepo.SetAttachmentScan(
+		ctx, achievementID, att, models.ScanInfected, result.Signature, quarantineKey,
+	)
+}
+
+func moveObject(ctx context.Context, st storage.Storage, from, to string) error {
+	r, info, err := st.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := st.Put(ctx, to, r, info.Size, info.ContentType); err != nil {
+		return err
+	}
+	return st.Delete(ctx, from)
+}
+
+// ScanSummary adalah hasil ScanPending (dipakai CLI)
+type ScanSummary struct {
+	Scanned  int
+	Clean    int
+	Infected int
+	Failed   int
+}
+
+// ScanPending men-scan ulang semua attachment yang masih pending / belum
+// pernah discan (attachment lama, atau scan gagal karena clamd mati).
+func (s *AchievementService) ScanPending(ctx context.Context) (*ScanSummary, error) {
+	docs, err := s.AchievementRepo.ListUnscannedAttachments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ScanSummary{}
+	for _, doc := range docs {
+		for _, att := range doc.Attachments {
+			if att.ScanState() != models.ScanPending {
+				continue
+			}
+
+			summary.Scanned++
+			status, err := s.ScanAttachment(ctx, doc.ID.Hex(), att)
+			switch {
+			case err != nil:
+				log.Println("scan", att.Key(), "failed:", err)
+				summary.Failed++
+			case status == models.ScanInfected:
+				summary.Infected++
+			default:
+				summary.Clean++
+				s.thumbnailAfterScan(ctx, doc.ID.Hex(), att)
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// ScanPendingAsync dipanggil saat server start: attachment yang diunggah
+// sebelum ada scan (scanStatus kosong) dianggap pending dan tidak bisa
+// dibuka verifikator / dosen wali sampai discan, jadi tidak perlu
+// menunggu seseorang menjalankan "go run . scan".
+func (s *AchievementService) ScanPendingAsync() {
+	go func() {
+		summary, err := s.ScanPending(context.Background())
+		if err != nil {
+			log.Println("startup attachment scan failed:", err)
+			return
+		}
+		if summary.Scanned > 0 {
+			log.Printf("startup attachment scan: scanned %d, clean %d, infected %d, failed %d",
+				summary.Scanned, summary.Clean, summary.Infected, summary.Failed)
+		}
+	}()
+}
+
+// canOpenAttachment: file infected tidak bisa dibuka siapa pun, file yang
+// belum clean hanya bisa dibuka pemiliknya (yang mengunggah), bukan
+// verifikator atau anggota tim lain.
+func canOpenAttachment(isOwner bool, att *models.AchievementAttachment) error {
+	switch att.ScanState() {
+	case models.ScanClean:
+		return nil
+	case models.ScanInfected:
+		return errors.New("attachment is quarantined: malware detected")
+	default:
+		if isOwner {
+			return nil
+		}
+		return errors.New("attachment has not passed malware scan yet")
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	_ "image/png" // decoder PNG untuk image.Decode
+	"log"
+
+	"golang.org/x/image/draw"
+
+	"pbluas/app/models"
+)
+
+// ================= THUMBNAIL =================
+// Thumbnail dibuat di background untuk attachment gambar yang sudah clean,
+// disimpan di samping file asli (<key>.thumb.jpg) lewat storage yang sama.
+
+const (
+	thumbnailMaxSize   = 320      // sisi terpanjang, pixel
+	thumbnailMaxPixels = 40000000 // tolak gambar raksasa (decompression bomb)
+	thumbnailQuality   = 80
+)
+
+func isImageAttachment(att models.AchievementAttachment) bool {
+	return att.FileType == "image/jpeg" || att.FileType == "image/png"
+}
+
+func thumbnailKey(att models.AchievementAttachment) string {
+	return att.Key() + ".thumb.jpg"
+}
+
+// generateThumbnail membuat thumbnail lalu menyimpan key-nya di attachment
+func (s *AchievementService) generateThumbnail(ctx context.Context, achievementID string, att models.AchievementAttachment) error {
+	r, _, err := s.Storage.Get(ctx, att.Key())
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var original bytes.Buffer
+	if _, err := original.ReadFrom(r); err != nil {
+		return err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(original.Bytes()))
+	if err != nil {
+		return err
+	}
+	if cfg.Width*cfg.Height > thumbnailMaxPixels {
+		return errors.New("image too large for thumbnail")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(original.Bytes()))
+	if err != nil {
+		return err
+	}
+
+	thumb := resizeToFit(src, thumbnailMaxSize)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, thumb, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return err
+	}
+
+	key := thumbnailKey(att)
+	if err := s.Storage.Put(ctx, key, &out, int64(out.Len()), "image/jpeg"); err != nil {
+		return err
+	}
+
+	return s.AchievementRepo.SetAttachmentThumbnail(ctx, achievementID, att, key)
+}
+
+// resizeToFit memperkecil gambar supaya sisi terpanjang <= limit,
+// gambar yang sudah kecil tidak diperbesar
+func resizeToFit(src image.Image, limit int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	if w > limit || h > limit {
+		if w >= h {
+			h = h * limit / w
+			w = limit
+		} else {
+			w = w * limit / h
+			h = limit
+		}
+	}
+
+	// JPEG tidak punya alpha: gambar di atas latar putih
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	return dst
+}
+
+// thumbnailAfterScan dipanggil setelah scan clean
+func (s *AchievementService) thumbnailAfterScan(ctx context.Context, achievementID string, att models.AchievementAttachment) {
+	if !isImageAttachment(att) || att.ThumbnailKey != "" {
+		return
+	}
+	if err := s.generateThumbnail(ctx, achievementID, att); err != nil {
+		log.Println("thumbnail", att.AttachmentID(), "failed:", err)
+	}
+}
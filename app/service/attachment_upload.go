@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"pbluas/app/models"
+)
+
+// ================= UPLOAD POLICY =================
+// Dikonfigurasi lewat env:
+//
+//	ATTACHMENT_ALLOWED_TYPES  daftar MIME dipisah koma (default PDF, JPEG, PNG)
+//	ATTACHMENT_MAX_FILE_MB    batas ukuran per file (default 5)
+//	ATTACHMENT_MAX_TOTAL_MB   batas total ukuran per achievement (default 20)
+
+// ekstensi file yang disimpan, ditentukan dari hasil sniffing (bukan dari client)
+var attachmentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+const maxFileNameLength = 200
+
+type uploadPolicy struct {
+	AllowedTypes map[string]bool
+	MaxFileSize  int64
+	MaxTotalSize int64
+}
+
+func attachmentUploadPolicy() uploadPolicy {
+	allowed := os.Getenv("ATTACHMENT_ALLOWED_TYPES")
+	if allowed == "" {
+		allowed = "application/pdf,image/jpeg,image/png"
+	}
+
+	policy := uploadPolicy{
+		AllowedTypes: make(map[string]bool),
+		MaxFileSize:  envMegabytes("ATTACHMENT_MAX_FILE_MB", 5),
+		MaxTotalSize: envMegabytes("ATTACHMENT_MAX_TOTAL_MB", 20),
+	}
+	for _, t := range strings.Split(allowed, ",") {
+		if t = strings.TrimSpace(strings.ToLower(t)); t != "" {
+			policy.AllowedTypes[t] = true
+		}
+	}
+
+	return policy
+}
+
+// MaxUploadBodySize dipakai main untuk BodyLimit Fiber
+// (ukuran file maksimum + ruang untuk header multipart)
+func MaxUploadBodySize() int {
+	return int(attachmentUploadPolicy().MaxFileSize) + 1024*1024
+}
+
+func envMegabytes(name string, def int64) int64 {
+	if mb, err := strconv.ParseInt(os.Getenv(name), 10, 64); err == nil && mb > 0 {
+		return mb * 1024 * 1024
+	}
+	return def * 1024 * 1024
+}
+
+// sniffedFile adalah file upload yang sudah lolos pemeriksaan
+type sniffedFile struct {
+	Reader      io.Reader // isi file lengkap (termasuk byte yang sudah di-sniff)
+	ContentType string
+	Extension   string
+	Size        int64
+}
+
+// checkUpload memeriksa ukuran dan isi file (magic bytes) terhadap policy.
+// existing = attachment yang sudah ada, untuk batas total per achievement.
+// Error yang dikembalikan aman ditampilkan ke client.
+func (p uploadPolicy) checkUpload(file *multipart.FileHeader, src io.Reader, existing []models.AchievementAttachment) (*sniffedFile, error) {
+	if file.Size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if file.Size > p.MaxFileSize {
+		return nil, fmt.Errorf("file exceeds maximum size of %d MB", p.MaxFileSize/1024/1024)
+	}
+
+	var total int64
+	for _, att := range existing {
+		total += att.Size
+	}
+	if total+file.Size > p.MaxTotalSize {
+		return nil, fmt.Errorf("total attachment size exceeds %d MB per achievement", p.MaxTotalSize/1024/1024)
+	}
+
+	// http.DetectContentType hanya membaca 512 byte pertama
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read file")
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	ext, known := attachmentExtensions[contentType]
+	if !known || !p.AllowedTypes[contentType] {
+		return nil, fmt.Errorf("file type %s is not allowed", contentType)
+	}
+
+	return &sniffedFile{
+		Reader:      io.MultiReader(bytes.NewReader(head), src),
+		ContentType: contentType,
+		Extension:   ext,
+		Size:        file.Size,
+	}, nil
+}
+
+// sanitizeFileName: nama file dari client hanya disimpan sebagai metadata.
+// Path, karakter kontrol dan karakter yang bermasalah di header dibuang.
+func sanitizeFileName(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return -1
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, name)
+
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	if name == "" {
+		name = "attachment" + ext
+	}
+
+	if len(name) > maxFileNameLength {
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		keep := maxFileNameLength - len(ext)
+		for len(base) > keep {
+			// potong per rune supaya UTF-8 tetap valid
+			_, size := utf8.DecodeLastRuneInString(base)
+			base = base[:len(base)-size]
+		}
+		name = base + ext
+	}
+
+	return name
+}
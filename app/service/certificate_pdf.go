@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// certificateData adalah isi yang dicetak di sertifikat
+type certificateData struct {
+	Code          string
+	VerifyURL     string
+	StudentName   string
+	StudentNumber string
+	ProgramStudy  string
+	Members       []string // nama anggota tim selain pemilik
+	Title         string
+	TypeLabel     string
+	Level         string
+	Detail        string
+	EventDate     string
+	VerifierName  string
+	VerifiedAt    time.Time
+	IssuedAt      time.Time
+}
+
+var indonesianMonths = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+func formatDateID(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// renderCertificatePDF membuat sertifikat A4 landscape dengan QR code
+// menuju halaman verifikasi publik
+func renderCertificatePDF(d certificateData) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle("Sertifikat Prestasi "+d.Code, true)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	// font bawaan gofpdf memakai cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageW, pageH := pdf.GetPageSize()
+
+	// bingkai
+	pdf.SetDrawColor(30, 60, 120)
+	pdf.SetLineWidth(1.5)
+	pdf.Rect(10, 10, pageW-20, pageH-20, "D")
+	pdf.SetLineWidth(0.4)
+	pdf.Rect(14, 14, pageW-28, pageH-28, "D")
+
+	center := func(y float64, size float64, style, text string) {
+		pdf.SetFont("Helvetica", style, size)
+		pdf.SetXY(20, y)
+		pdf.CellFormat(pageW-40, size*0.5, tr(text), "", 0, "C", false, 0, "")
+	}
+
+	pdf.SetTextColor(30, 60, 120)
+	center(28, 28, "B", "SERTIFIKAT PRESTASI")
+	pdf.SetTextColor(60, 60, 60)
+	center(42, 12, "", "Diberikan kepada")
+
+	pdf.SetTextColor(0, 0, 0)
+	center(54, 24, "B", d.StudentName)
+	center(68, 12, "", "NIM "+d.StudentNumber+" - "+d.ProgramStudy)
+
+	if len(d.Members) > 0 {
+		center(76, 10, "I", "bersama tim: "+strings.Join(d.Members, ", "))
+	}
+
+	pdf.SetTextColor(60, 60, 60)
+	center(88, 12, "", "atas prestasi")
+	pdf.SetTextColor(0, 0, 0)
+	center(98, 18, "B", d.Title)
+
+	info := d.TypeLabel
+	if d.Level != "" && d.Level != "unknown" {
+		info += " - tingkat " + d.Level
+	}
+	center(110, 12, "", info)
+	if d.Detail != "" {
+		center(118, 11, "", d.Detail)
+	}
+	if d.EventDate != "" {
+		if t, err := time.Parse("2006-01-02", d.EventDate); err == nil {
+			center(126, 11, "", "Tanggal kegiatan: "+formatDateID(t))
+		}
+	}
+
+	// blok verifikasi (kiri bawah)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetXY(30, 150)
+	pdf.CellFormat(120, 6, tr("Diverifikasi oleh: "+d.VerifierName), "", 2, "L", false, 0, "")
+	pdf.CellFormat(120, 6, tr("Tanggal verifikasi: "+formatDateID(d.VerifiedAt)), "", 2, "L", false, 0, "")
+	pdf.CellFormat(120, 6, tr("Tanggal terbit: "+formatDateID(d.IssuedAt)), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(120, 6, "Kode sertifikat: "+d.Code, "", 2, "L", false, 0, "")
+
+	// QR code (kanan bawah)
+	png, err := qrcode.Encode(d.VerifyURL, qrcode.Medium, 512)
+	if err != nil {
+		return nil, err
+	}
+	opt := gofpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("qr", opt, bytes.NewReader(png))
+	qrSize := 38.0
+	qrX := pageW - 30 - qrSize
+	pdf.ImageOptions("qr", qrX, 138, qrSize, qrSize, false, opt, 0, "")
+
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetXY(qrX-20, 138+qrSize+1)
+	pdf.CellFormat(qrSize+40, 4, "Pindai untuk memeriksa keaslian", "", 2, "C", false, 0, "")
+	pdf.SetX(qrX - 20)
+	pdf.CellFormat(qrSize+40, 4, d.VerifyURL, "", 0, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+)
+
+type CertificateService struct {
+	Repo         *repository.CertificateRepository
+	Achievements *AchievementService // aturan akses & repository achievement
+	UserRepo     repository.UserRepository
+}
+
+func NewCertificateService(
+	repo *repository.CertificateRepository,
+	achievements *AchievementService,
+	userRepo repository.UserRepository,
+) *CertificateService {
+	return &CertificateService{
+		Repo:         repo,
+		Achievements: achievements,
+		UserRepo:     userRepo,
+	}
+}
+
+// verifyURL: URL publik yang dicetak di QR code sertifikat
+func verifyURL(code string) string {
+	base := os.Getenv("PUBLIC_BASE_URL")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return strings.TrimRight(base, "/") + "/verify/" + code
+}
+
+func (s *CertificateService) userName(userID *string) string {
+	if userID == nil {
+		return "-"
+	}
+	user, err := s.UserRepo.FindByUserID(*userID)
+	if err != nil {
+		return "-"
+	}
+	return user.FullName
+}
+
+// maskStudentNumber: NIM di halaman publik hanya ditampilkan sebagian
+func maskStudentNumber(nim string) string {
+	if len(nim) <= 4 {
+		return nim
+	}
+	return nim[:2] + strings.Repeat("*", len(nim)-4) + nim[len(nim)-2:]
+}
+
+// DownloadCertificate godoc
+// @Summary Download achievement certificate
+// @Description PDF certificate for a verified achievement, with a QR code pointing to the public verification page. Same access rules as achievement detail.
+// @Tags Achievements
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path string true "Achievement ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /achievements/{id}/certificate [get]
+func (s *CertificateService) Download(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	mongoID := c.Params("id")
+
+	// 1️⃣ reference + hak akses
+	ref, err := s.Achievements.ReferenceRepo.GetByMongoID(mongoID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement not found",
+		})
+	}
+
+	if !s.Achievements.canView(role, userID, ref) {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "forbidden",
+		})
+	}
+
+	if ref.Status != "verified" {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "certificate is only available for verified achievement",
+		})
+	}
+
+	// 2️⃣ data achievement & student
+	achievement, err := s.Achievements.AchievementRepo.FindByID(context.Background(), mongoID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "achievement detail not found",
+		})
+	}
+
+	student, err := s.Achievements.StudentRepo.GetStudentByID(ref.StudentID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "student not found",
+		})
+	}
+
+	members, err := s.Achievements.MemberRepo.GetByMongoID(mongoID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	var memberNames []string
+	for _, m := range members {
+		if m.StudentID == ref.StudentID {
+			continue
+		}
+		if ms, err := s.Achievements.StudentRepo.GetStudentByID(m.StudentID); err == nil {
+			memberNames = append(memberNames, ms.FullName)
+		}
+	}
+
+	// 3️⃣ code sertifikat (dibuat sekali, dipakai ulang)
+	cert, err := s.Repo.GetOrCreate(mongoID, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	typeLabel := achievement.AchievementType
+	if spec := models.GetAchievementTypeSpec(achievement.AchievementType); spec != nil {
+		typeLabel = spec.Label
+	}
+
+	data := certificateData{
+		Code:          cert.Code,
+		VerifyURL:     verifyURL(cert.Code),
+		StudentName:   student.FullName,
+		StudentNumber: student.StudentID,
+		ProgramStudy:  student.ProgramStudy,
+		Members:       memberNames,
+		Title:         achievement.Title,
+		TypeLabel:     typeLabel,
+		Level:         models.ReportLevel(achievement.AchievementType, achievement.Details),
+		Detail:        models.DetailSummary(achievement.AchievementType, achievement.Details),
+		EventDate:     achievement.Details.EventDate,
+		VerifierName:  s.userName(ref.VerifiedBy),
+		IssuedAt:      cert.IssuedAt,
+	}
+	if ref.VerifiedAt != nil {
+		data.VerifiedAt = *ref.VerifiedAt
+	}
+
+	pdf, err := renderCertificatePDF(data)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="sertifikat-`+cert.Code+`.pdf"`)
+	return c.Send(pdf)
+}
+
+// VerifyCertificate godoc
+// @Summary Verify certificate (public)
+// @Description Public, unauthenticated check of a certificate code. Shows the current status of the achievement, including revocation.
+// @Tags Certificates
+// @Produce json
+// @Param code path string true "Certificate code"
+// @Success 200 {object} models.CertificateVerification
+// @Failure 404 {object} map[string]interface{}
+// @Router /verify/{code} [get]
+func (s *CertificateService) Verify(c *fiber.Ctx) error {
+	code := strings.ToUpper(strings.TrimSpace(c.Params("code")))
+
+	cert, err := s.Repo.GetByCode(code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(fiber.Map{
+			"valid":   false,
+			"message": "certificate not found",
+		})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	result := models.CertificateVerification{
+		Code:     cert.Code,
+		IssuedAt: cert.IssuedAt,
+	}
+
+	// achievement yang sudah dihapus tidak lagi punya reference aktif
+	ref, err := s.Achievements.ReferenceRepo.GetByMongoID(cert.MongoID)
+	if err != nil {
+		result.Status = "deleted"
+		return c.JSON(result)
+	}
+
+	result.Status = ref.Status
+	result.Valid = ref.Status == "verified"
+	result.VerifiedAt = ref.VerifiedAt
+	result.VerifiedBy = s.userName(ref.VerifiedBy)
+	result.RevokedAt = ref.RevokedAt
+	result.RevocationReason = ref.RevocationReason
+
+	if student, err := s.Achievements.StudentRepo.GetStudentByID(ref.StudentID); err == nil {
+		result.StudentName = student.FullName
+		result.StudentNumber = maskStudentNumber(student.StudentID)
+		result.ProgramStudy = student.ProgramStudy
+	}
+
+	if achievement, err := s.Achievements.AchievementRepo.FindByID(context.Background(), cert.MongoID); err == nil {
+		result.Title = achievement.Title
+		result.AchievementType = achievement.AchievementType
+		result.Level = models.ReportLevel(achievement.AchievementType, achievement.Details)
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+)
+
+// Kategori inkonsistensi antara achievement_references (Postgres) dan
+// koleksi achievements (Mongo)
+const (
+	IssueDanglingReference  = "dangling_reference"  // reference menunjuk dokumen Mongo yang tidak ada
+	IssueDuplicateReference = "duplicate_reference" // lebih dari satu reference aktif untuk satu dokumen
+	IssueOrphanDocument     = "orphan_document"     // dokumen Mongo tanpa reference sama sekali
+	IssueStudentMismatch    = "student_mismatch"    // studentId di Mongo beda dengan student_id di Postgres
+	IssueUnknownStudent     = "unknown_student"     // student_id tidak ada di tabel students
+)
+
+var ConsistencyCategories = []string{
+	IssueDanglingReference,
+	IssueDuplicateReference,
+	IssueOrphanDocument,
+	IssueStudentMismatch,
+	IssueUnknownStudent,
+}
+
+type ConsistencyIssue struct {
+	Category       string `json:"category"`
+	ReferenceID    string `json:"reference_id,omitempty"`
+	MongoID        string `json:"mongo_id,omitempty"`
+	StudentID      string `json:"student_id,omitempty"`
+	MongoStudentID string `json:"mongo_student_id,omitempty"`
+	Action         string `json:"action,omitempty"`
+	Repaired       bool   `json:"repaired"`
+	Error          string `json:"error,omitempty"`
+}
+
+type ConsistencyReport struct {
+	CheckedReferences int                 `json:"checked_references"`
+	CheckedDocuments  int                 `json:"checked_documents"`
+	Counts            map[string]int      `json:"counts"`
+	Issues            []*ConsistencyIssue `json:"issues"`
+	DryRun            bool                `json:"dry_run"`
+}
+
+type ConsistencyService struct {
+	AchievementRepo *repository.AchievementRepository
+	ReferenceRepo   *repository.AchievementReferenceRepository
+	StudentRepo     repository.StudentRepository
+}
+
+func NewConsistencyService(
+	ar *repository.AchievementRepository,
+	rr *repository.AchievementReferenceRepository,
+	sr repository.StudentRepository,
+) *ConsistencyService {
+	return &ConsistencyService{
+		AchievementRepo: ar,
+		ReferenceRepo:   rr,
+		StudentRepo:     sr,
+	}
+}
+
+// Check memindai kedua store dan mengembalikan semua inkonsistensi.
+// Setiap issue sudah berisi rencana perbaikan (Action) tapi belum dijalankan.
+func (s *ConsistencyService) Check(ctx context.Context) (*ConsistencyReport, error) {
+	refs, err := s.ReferenceRepo.GetAllIncludingDeleted()
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := s.AchievementRepo.ListOwnership(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.StudentRepo.GetAllStudents()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConsistencyReport{
+		CheckedReferences: len(refs),
+		CheckedDocuments:  len(docs),
+		Counts:            map[string]int{},
+		DryRun:            true,
+	}
+	for _, c := range ConsistencyCategories {
+		report.Counts[c] = 0
+	}
+
+	add := func(issue *ConsistencyIssue) {
+		report.Issues = append(report.Issues, issue)
+		report.Counts[issue.Category]++
+	}
+
+	knownStudents := make(map[string]bool)
+	for _, st := range students {
+		knownStudents[st.ID] = true
+	}
+
+	docOwner := make(map[string]string) // mongoID -> studentId
+	for _, d := range docs {
+		docOwner[d.ID.Hex()] = d.StudentID
+	}
+
+	// mongoID yang punya reference (termasuk yang sudah soft delete)
+	referenced := make(map[string]bool)
+	// reference aktif pertama per mongoID (urut created_at)
+	activeRef := make(map[string]models.AchievementReference)
+
+	// ================= CEK REFERENCES =================
+	for _, ref := range refs {
+		referenced[ref.MongoID] = true
+
+		if !knownStudents[ref.StudentID] {
+			add(&ConsistencyIssue{
+				Category:    IssueUnknownStudent,
+				ReferenceID: ref.ID,
+				MongoID:     ref.MongoID,
+				StudentID:   ref.StudentID,
+			})
+		}
+
+		if ref.Status == "deleted" {
+			continue
+		}
+
+		owner, exists := docOwner[ref.MongoID]
+		if !exists {
+			add(&ConsistencyIssue{
+				Category:    IssueDanglingReference,
+				ReferenceID: ref.ID,
+				MongoID:     ref.MongoID,
+				StudentID:   ref.StudentID,
+				Action:      "soft delete reference",
+			})
+			continue
+		}
+
+		if first, dup := activeRef[ref.MongoID]; dup {
+			add(&ConsistencyIssue{
+				Category:    IssueDuplicateReference,
+				ReferenceID: ref.ID,
+				MongoID:     ref.MongoID,
+				StudentID:   ref.StudentID,
+				Action:      "soft delete reference (keep " + first.ID + ")",
+			})
+			continue
+		}
+		activeRef[ref.MongoID] = ref
+
+		if owner != ref.StudentID {
+			add(&ConsistencyIssue{
+				Category:       IssueStudentMismatch,
+				ReferenceID:    ref.ID,
+				MongoID:        ref.MongoID,
+				StudentID:      ref.StudentID,
+				MongoStudentID: owner,
+				Action:         "set mongo studentId to " + ref.StudentID,
+			})
+		}
+	}
+
+	// ================= CEK DOKUMEN MONGO =================
+	for _, d := range docs {
+		id := d.ID.Hex()
+		if referenced[id] {
+			continue
+		}
+
+		issue := &ConsistencyIssue{
+			Category:       IssueOrphanDocument,
+			MongoID:        id,
+			MongoStudentID: d.StudentID,
+		}
+		if knownStudents[d.StudentID] {
+			issue.Action = "create draft reference for student " + d.StudentID
+		}
+		add(issue)
+	}
+
+	return report, nil
+}
+
+// Repair menjalankan Action dari setiap issue. Issue tanpa Action
+// (unknown_student, orphan milik student yang tidak dikenal) hanya dilaporkan.
+func (s *ConsistencyService) Repair(ctx context.Context, report *ConsistencyReport) {
+	report.DryRun = false
+
+	for _, issue := range report.Issues {
+		if issue.Action == "" {
+			continue
+		}
+
+		var err error
+		switch issue.Category {
+
+		case IssueDanglingReference, IssueDuplicateReference:
+			err = s.ReferenceRepo.SoftDelete(issue.ReferenceID)
+
+		case IssueStudentMismatch:
+			err = s.AchievementRepo.UpdateStudentID(ctx, issue.MongoID, issue.StudentID)
+
+		case IssueOrphanDocument:
+			err = s.ReferenceRepo.Create(&models.AchievementReference{
+				StudentID: issue.MongoStudentID,
+				MongoID:   issue.MongoID,
+			})
+		}
+
+		if err != nil {
+			issue.Error = err.Error()
+			continue
+		}
+		issue.Repaired = true
+	}
+}
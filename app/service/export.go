@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+)
+
+// Format export yang didukung lewat query ?format=
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// exportFormat membaca ?format=, default json
+func exportFormat(c *fiber.Ctx) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", FormatJSON)))
+	switch format {
+	case FormatJSON, FormatCSV, FormatXLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("format must be one of: json csv xlsx")
+	}
+}
+
+// exportFormatError: response 400 untuk ?format= yang tidak dikenal
+func exportFormatError(c *fiber.Ctx, err error) error {
+	return c.Status(400).JSON(fiber.Map{
+		"message": err.Error(),
+	})
+}
+
+// exportRows dipanggil saat body ditulis; emit satu baris per panggilan.
+// Jangan memakai *fiber.Ctx di dalamnya karena ctx sudah dilepas saat
+// body di-stream.
+type exportRows func(emit func(row []interface{}) error) error
+
+// sendExport menulis tabel sebagai CSV / XLSX secara streaming.
+// Header kolom dipakai apa adanya, jadi harus stabil antar versi.
+func sendExport(c *fiber.Ctx, format, filename string, headers []string, rows exportRows) error {
+	filename = fmt.Sprintf("%s-%s.%s", filename, time.Now().Format("20060102"), format)
+
+	switch format {
+	case FormatCSV:
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	case FormatXLSX:
+		c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var err error
+		if format == FormatXLSX {
+			err = writeXLSX(w, headers, rows)
+		} else {
+			err = writeCSV(w, headers, rows)
+		}
+		if err != nil {
+			// header sudah terkirim, hanya bisa dicatat
+			log.Println("export", filename, "failed:", err)
+		}
+	})
+
+	return nil
+}
+
+func writeCSV(w *bufio.Writer, headers []string, rows exportRows) error {
+	cw := csv.NewWriter(w)
+
+	// BOM supaya Excel membaca UTF-8 dengan benar
+	if _, err := w.WriteString("\ufeff"); err != nil {
+		return err
+	}
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	n := 0
+	err := rows(func(row []interface{}) error {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = csvCell(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+
+		// kirim ke client per 500 baris
+		n++
+		if n%500 == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvCell: teks yang diawali = + - @ diberi prefix ' supaya tidak
+// dieksekusi sebagai formula saat dibuka di spreadsheet
+func csvCell(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if val != "" && strings.ContainsRune("=+-@\t\r", rune(val[0])) {
+			return "'" + val
+		}
+		return val
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return csvCell(*val)
+	case *string:
+		if val == nil {
+			return ""
+		}
+		return csvCell(*val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func writeXLSX(w *bufio.Writer, headers []string, rows exportRows) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Sheet1"
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(headers))
+	for i, h := range headers {
+		header[i] = h
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	rowNum := 1
+	err = rows(func(row []interface{}) error {
+		rowNum++
+		cell, err := excelize.CoordinatesToCellName(1, rowNum)
+		if err != nil {
+			return err
+		}
+
+		values := make([]interface{}, len(row))
+		for i, v := range row {
+			values[i] = xlsxCell(v)
+		}
+		return sw.SetRow(cell, values)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// xlsxCell: pointer & waktu diubah ke nilai yang dimengerti excelize
+func xlsxCell(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		if val.IsZero() {
+			return nil
+		}
+		return val.Format("2006-01-02 15:04:05")
+	case *time.Time:
+		if val == nil {
+			return nil
+		}
+		return xlsxCell(*val)
+	case *string:
+		if val == nil {
+			return nil
+		}
+		return *val
+	default:
+		return val
+	}
+}
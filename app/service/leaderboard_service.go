@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pbluas/app/models"
+	"pbluas/validation"
+)
+
+const (
+	defaultLeaderboardLimit = 20
+	topStudentsLimit        = 5
+)
+
+// parseStatisticsQuery: filter + validasi yang sama dengan /reports/statistics
+func parseStatisticsQuery(c *fiber.Ctx, extra ...interface{}) (models.StatisticsFilter, []validation.FieldError, error) {
+	var filter models.StatisticsFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return filter, nil, err
+	}
+	errs := validation.Struct(&filter)
+
+	for _, e := range extra {
+		if err := c.QueryParser(e); err != nil {
+			return filter, nil, err
+		}
+		errs = append(errs, validation.Struct(e)...)
+	}
+
+	return filter, errs, nil
+}
+
+// loadPointData: penerima poin (SQL) + poin tiap achievement (Mongo)
+// yang lolos filter
+func (s *ReportService) loadPointData(ctx context.Context, filter models.StatisticsFilter) ([]models.PointHolder, *models.AchievementAggregate, error) {
+	holders, err := s.RefRepo.GetVerifiedPointHolders(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := map[string]bool{}
+	var mongoIDs []string
+	for _, h := range holders {
+		if !seen[h.MongoID] {
+			seen[h.MongoID] = true
+			mongoIDs = append(mongoIDs, h.MongoID)
+		}
+	}
+
+	agg, err := s.AchievementRepo.AggregateStatistics(ctx, mongoIDs, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return holders, agg, nil
+}
+
+// rankStudents menyusun leaderboard lengkap. Holder yang achievement-nya
+// tersaring di Mongo tidak ada di agg.Points sehingga dilewati.
+func (s *ReportService) rankStudents(holders []models.PointHolder, agg *models.AchievementAggregate) ([]models.LeaderboardEntry, error) {
+	points := map[string]int{}
+	counts := map[string]int{}
+	for _, h := range holders {
+		p, ok := agg.Points[h.MongoID]
+		if !ok {
+			continue
+		}
+		// achievement beregu: poin masuk ke setiap anggota
+		points[h.StudentID] += memberPoints(p.Points, p.PointRule, h.MemberCount)
+		counts[h.StudentID]++
+	}
+
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+
+	students, err := s.StudentRepo.GetStudentsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(ids))
+	for _, id := range ids {
+		st, ok := students[id]
+		if !ok {
+			continue // data mahasiswa sudah tidak ada
+		}
+		entries = append(entries, models.LeaderboardEntry{
+			ID:           id,
+			StudentID:    st.StudentID,
+			Name:         st.FullName,
+			ProgramStudy: st.ProgramStudy,
+			AcademicYear: st.AcademicYear,
+			Points:       points[id],
+			Achievements: counts[id],
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Achievements != b.Achievements {
+			return a.Achievements > b.Achievements
+		}
+		if a.StudentID != b.StudentID {
+			return a.StudentID < b.StudentID
+		}
+		return a.ID < b.ID
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Points == entries[i-1].Points {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries, nil
+}
+
+// GetLeaderboard godoc
+// @Summary Student leaderboard
+// @Description Students ranked by verified points. Ties are ordered by number of achievements, then NIM; equal points share a rank. Accepts the same filters as /reports/statistics. Requires report:view.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param from query string false "Period start (YYYY-MM-DD)"
+// @Param to query string false "Period end, inclusive (YYYY-MM-DD)"
+// @Param date_field query string false "Date used for the period: event (default) or verified"
+// @Param program_study query string false "Program study"
+// @Param academic_year query string false "Cohort (academic year)"
+// @Param advisor_id query string false "Lecturer ID of the advisor"
+// @Param type query string false "Achievement type"
+// @Param level query string false "Competition level"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/leaderboard [get]
+func (s *ReportService) GetLeaderboard(c *fiber.Ctx) error {
+	var page models.Pagination
+	filter, errs, err := parseStatisticsQuery(c, &page)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid query parameters",
+		})
+	}
+	if errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	if page.Page == 0 {
+		page.Page = 1
+	}
+	if page.Limit == 0 {
+		page.Limit = defaultLeaderboardLimit
+	}
+
+	entries, err := s.buildLeaderboard(c.Context(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	total := len(entries)
+	start := min((page.Page-1)*page.Limit, total)
+	end := min(start+page.Limit, total)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    entries[start:end],
+		"pagination": models.PageInfo{
+			Page:       page.Page,
+			Limit:      page.Limit,
+			Total:      total,
+			TotalPages: (total + page.Limit - 1) / page.Limit,
+		},
+		"filters": filter,
+	})
+}
+
+// GetProgramComparison godoc
+// @Summary Compare program studies
+// @Description Per program study: students, students with at least one verified achievement (and their share), total achievements and points, and per-student averages. Accepts the same filters as /reports/statistics. Requires report:view.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Period start (YYYY-MM-DD)"
+// @Param to query string false "Period end, inclusive (YYYY-MM-DD)"
+// @Param date_field query string false "Date used for the period: event (default) or verified"
+// @Param program_study query string false "Program study"
+// @Param academic_year query string false "Cohort (academic year)"
+// @Param advisor_id query string false "Lecturer ID of the advisor"
+// @Param type query string false "Achievement type"
+// @Param level query string false "Competition level"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/programs/comparison [get]
+func (s *ReportService) GetProgramComparison(c *fiber.Ctx) error {
+	filter, errs, err := parseStatisticsQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid query parameters",
+		})
+	}
+	if errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	result, err := s.buildProgramComparison(c.Context(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"filters": filter,
+	})
+}
+
+// buildLeaderboard: leaderboard lengkap (tanpa pagination)
+func (s *ReportService) buildLeaderboard(ctx context.Context, filter models.StatisticsFilter) ([]models.LeaderboardEntry, error) {
+	holders, agg, err := s.loadPointData(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.rankStudents(holders, agg)
+}
+
+func (s *ReportService) buildProgramComparison(ctx context.Context, filter models.StatisticsFilter) ([]models.ProgramComparison, error) {
+	holders, agg, err := s.loadPointData(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.rankStudents(holders, agg)
+	if err != nil {
+		return nil, err
+	}
+
+	// jumlah mahasiswa per prodi (pembagi rata-rata)
+	studentCounts, err := s.StudentRepo.CountByProgramStudy(filter.AcademicYear, filter.ProgramStudy)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := map[string]*models.ProgramComparison{}
+	get := func(name string) *models.ProgramComparison {
+		if p, ok := programs[name]; ok {
+			return p
+		}
+		p := &models.ProgramComparison{ProgramStudy: name}
+		programs[name] = p
+		return p
+	}
+
+	for name, n := range studentCounts {
+		get(name).Students = n
+	}
+
+	// achievement beregu lintas prodi dihitung sekali per prodi
+	studentProgram := map[string]string{}
+	for _, e := range entries {
+		p := get(e.ProgramStudy)
+		p.ActiveStudents++
+		p.TotalPoints += e.Points
+		studentProgram[e.ID] = e.ProgramStudy
+	}
+
+	counted := map[[2]string]bool{}
+	for _, h := range holders {
+		if _, ok := agg.Points[h.MongoID]; !ok {
+			continue
+		}
+		program, ok := studentProgram[h.StudentID]
+		if !ok {
+			continue
+		}
+		key := [2]string{program, h.MongoID}
+		if !counted[key] {
+			counted[key] = true
+			get(program).TotalAchievements++
+		}
+	}
+
+	result := make([]models.ProgramComparison, 0, len(programs))
+	for _, p := range programs {
+		if p.Students > 0 {
+			p.ActiveShare = round2(float64(p.ActiveStudents) / float64(p.Students))
+			p.AchievementsPerStudent = round2(float64(p.TotalAchievements) / float64(p.Students))
+			p.PointsPerStudent = round2(float64(p.TotalPoints) / float64(p.Students))
+		}
+		result = append(result, *p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalPoints != result[j].TotalPoints {
+			return result[i].TotalPoints > result[j].TotalPoints
+		}
+		return result[i].ProgramStudy < result[j].ProgramStudy
+	})
+
+	return result, nil
+}
+
+func round2(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
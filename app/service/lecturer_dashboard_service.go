@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pbluas/app/models"
+)
+
+// currentSemester: ganjil Agustus–Januari, genap Februari–Juli
+func currentSemester(now time.Time) models.Semester {
+	year, month := now.Year(), now.Month()
+	loc := now.Location()
+
+	switch {
+	case month >= time.February && month <= time.July:
+		return models.Semester{
+			Name:  fmt.Sprintf("Genap %d/%d", year-1, year),
+			Start: time.Date(year, time.February, 1, 0, 0, 0, 0, loc),
+			End:   time.Date(year, time.August, 1, 0, 0, 0, 0, loc),
+		}
+	case month >= time.August:
+		return models.Semester{
+			Name:  fmt.Sprintf("Ganjil %d/%d", year, year+1),
+			Start: time.Date(year, time.August, 1, 0, 0, 0, 0, loc),
+			End:   time.Date(year+1, time.February, 1, 0, 0, 0, 0, loc),
+		}
+	default: // Januari, masih semester ganjil tahun lalu
+		return models.Semester{
+			Name:  fmt.Sprintf("Ganjil %d/%d", year-1, year),
+			Start: time.Date(year-1, time.August, 1, 0, 0, 0, 0, loc),
+			End:   time.Date(year, time.February, 1, 0, 0, 0, 0, loc),
+		}
+	}
+}
+
+// MyDashboard godoc
+// @Summary Advisor dashboard (own advisees)
+// @Description Summary of the logged-in Dosen Wali's advisees: achievement counts by status, verified points, pending verification queue with ages, and advisees without achievements this semester. Counts and points include team achievements the advisee is a member of.
+// @Tags Lecturers
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.AdvisorDashboard
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /lecturers/me/dashboard [get]
+func (s *LecturerService) MyDashboard(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	if role != "Dosen" && role != "Dosen Wali" && role != "Lecturer" {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "only lecturers have an advisor dashboard",
+		})
+	}
+
+	lecturer, err := s.LecturerRepo.GetLecturerByUserID(userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "lecturer profile not found",
+		})
+	}
+
+	return s.sendDashboard(c, lecturer)
+}
+
+// LecturerDashboard godoc
+// @Summary Advisor dashboard of a lecturer (Admin)
+// @Description Same as /lecturers/me/dashboard for any lecturer.
+// @Tags Lecturers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Lecturer ID"
+// @Success 200 {object} models.AdvisorDashboard
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /lecturers/{id}/dashboard [get]
+func (s *LecturerService) LecturerDashboard(c *fiber.Ctx) error {
+	lecturer, err := s.LecturerRepo.GetLecturerByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "lecturer not found",
+		})
+	}
+
+	return s.sendDashboard(c, lecturer)
+}
+
+func (s *LecturerService) sendDashboard(c *fiber.Ctx, lecturer *models.Lecturer) error {
+	dashboard, err := s.buildDashboard(c.Context(), lecturer, time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    dashboard,
+	})
+}
+
+func (s *LecturerService) buildDashboard(ctx context.Context, lecturer *models.Lecturer, now time.Time) (*models.AdvisorDashboard, error) {
+	semester := currentSemester(now)
+
+	// 1️⃣ mahasiswa perwalian
+	students, err := s.StudentRepo.GetStudentsByAdvisor(lecturer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	advisees := make([]models.AdviseeSummary, len(students))
+	index := make(map[string]int, len(students))
+	var studentIDs []string
+	for i, st := range students {
+		advisees[i] = models.AdviseeSummary{
+			ID:           st.ID,
+			StudentID:    st.StudentID,
+			Name:         st.FullName,
+			ProgramStudy: st.ProgramStudy,
+			AcademicYear: st.AcademicYear,
+			StatusCounts: map[string]int{},
+		}
+		index[st.ID] = i
+		studentIDs = append(studentIDs, st.ID)
+	}
+
+	// 2️⃣ jumlah achievement per status (satu query, termasuk achievement beregu)
+	counts, err := s.RefRepo.CountByStudentStatus(studentIDs, semester.Start)
+	if err != nil {
+		return nil, err
+	}
+	for _, cnt := range counts {
+		a := &advisees[index[cnt.StudentID]]
+		a.StatusCounts[cnt.Status] = cnt.Total
+		a.TotalAchievements += cnt.Total
+		a.SemesterAchievements += cnt.SinceCount
+	}
+
+	// 3️⃣ poin verified (termasuk achievement beregu)
+	holders, err := s.RefRepo.GetVerifiedPointHolders(models.StatisticsFilter{AdvisorID: lecturer.ID})
+	if err != nil {
+		return nil, err
+	}
+	var mongoIDs []string
+	for _, h := range holders {
+		mongoIDs = append(mongoIDs, h.MongoID)
+	}
+	agg, err := s.AchievementRepo.AggregateStatistics(ctx, mongoIDs, models.StatisticsFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &models.AdvisorDashboard{
+		Lecturer:         *lecturer,
+		Semester:         semester,
+		PendingQueue:     []models.PendingVerification{},
+		InactiveAdvisees: []models.AdviseeSummary{},
+	}
+
+	for _, h := range holders {
+		i, ok := index[h.StudentID]
+		p, found := agg.Points[h.MongoID]
+		if !ok || !found {
+			continue
+		}
+		points := memberPoints(p.Points, p.PointRule, h.MemberCount)
+		advisees[i].VerifiedPoints += points
+		dashboard.Summary.TotalVerifiedPoints += points
+	}
+
+	// 4️⃣ antrean verifikasi
+	pending, err := s.RefRepo.GetSubmittedByStudentIDs(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingIDs []string
+	for _, ref := range pending {
+		pendingIDs = append(pendingIDs, ref.MongoID)
+	}
+	achievements, err := s.AchievementRepo.FindByIDs(ctx, pendingIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Achievement, len(achievements))
+	for _, a := range achievements {
+		byID[a.ID.Hex()] = a
+	}
+
+	for _, ref := range pending {
+		item := models.PendingVerification{
+			AchievementID: ref.MongoID,
+			StudentID:     ref.StudentID,
+			SubmittedAt:   ref.SubmittedAt,
+		}
+		if i, ok := index[ref.StudentID]; ok {
+			item.StudentName = advisees[i].Name
+		}
+		if a, ok := byID[ref.MongoID]; ok {
+			item.Title = a.Title
+			item.AchievementType = a.AchievementType
+		}
+
+		since := ref.CreatedAt
+		if ref.SubmittedAt != nil {
+			since = *ref.SubmittedAt
+		}
+		item.AgeDays = int(now.Sub(since).Hours() / 24)
+
+		dashboard.PendingQueue = append(dashboard.PendingQueue, item)
+		dashboard.Summary.OldestPendingDays = max(dashboard.Summary.OldestPendingDays, item.AgeDays)
+	}
+
+	// 5️⃣ mahasiswa tanpa achievement semester ini
+	for _, a := range advisees {
+		if a.SemesterAchievements == 0 {
+			dashboard.InactiveAdvisees = append(dashboard.InactiveAdvisees, a)
+		}
+	}
+
+	dashboard.Advisees = advisees
+	dashboard.Summary.TotalAdvisees = len(advisees)
+	dashboard.Summary.PendingCount = len(dashboard.PendingQueue)
+	dashboard.Summary.InactiveCount = len(dashboard.InactiveAdvisees)
+
+	return dashboard, nil
+}
@@ -6,17 +6,23 @@ import (
 )
 
 type LecturerService struct {
-	LecturerRepo repository.LecturerRepository
-	StudentRepo  repository.StudentRepository
+	LecturerRepo    repository.LecturerRepository
+	StudentRepo     repository.StudentRepository
+	RefRepo         *repository.AchievementReferenceRepository
+	AchievementRepo *repository.AchievementRepository
 }
 
 func NewLecturerService(
 	lecturerRepo repository.LecturerRepository,
 	studentRepo repository.StudentRepository,
+	refRepo *repository.AchievementReferenceRepository,
+	achievementRepo *repository.AchievementRepository,
 ) *LecturerService {
 	return &LecturerService{
-		LecturerRepo: lecturerRepo,
-		StudentRepo:  studentRepo,
+		LecturerRepo:    lecturerRepo,
+		StudentRepo:     studentRepo,
+		RefRepo:         refRepo,
+		AchievementRepo: achievementRepo,
 	}
 }
 
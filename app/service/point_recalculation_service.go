@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+	"pbluas/validation"
+)
+
+// jumlah dokumen Mongo yang diambil per query $in
+const recalcBatchSize = 1000
+
+type PointRecalculationService struct {
+	Repo            *repository.PointRecalcRepository
+	PointRules      *PointRuleService
+	AchievementRepo *repository.AchievementRepository
+	RefRepo         *repository.AchievementReferenceRepository
+	MemberRepo      *repository.AchievementMemberRepository
+
+	// hanya satu job yang boleh berjalan dalam satu waktu
+	running sync.Mutex
+}
+
+func NewPointRecalculationService(
+	repo *repository.PointRecalcRepository,
+	pointRules *PointRuleService,
+	achievementRepo *repository.AchievementRepository,
+	refRepo *repository.AchievementReferenceRepository,
+	memberRepo *repository.AchievementMemberRepository,
+) *PointRecalculationService {
+	return &PointRecalculationService{
+		Repo:            repo,
+		PointRules:      pointRules,
+		AchievementRepo: achievementRepo,
+		RefRepo:         refRepo,
+		MemberRepo:      memberRepo,
+	}
+}
+
+// ================= JOB RUNNER =================
+
+func (s *PointRecalculationService) start(job *models.PointRecalcJob) error {
+	if !s.running.TryLock() {
+		return fmt.Errorf("another recalculation job is running")
+	}
+
+	if err := s.Repo.CreateJob(job); err != nil {
+		s.running.Unlock()
+		return err
+	}
+
+	go func() {
+		defer s.running.Unlock()
+
+		var total, changed int
+		var err error
+
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+			if ferr := s.Repo.MarkFinished(job.ID, total, changed, err); ferr != nil {
+				log.Println("recalc job", job.ID, "failed to finish:", ferr)
+			}
+		}()
+
+		total, changed, err = s.execute(context.Background(), job)
+	}()
+
+	return nil
+}
+
+// execute menghitung ulang poin achievement dalam scope job dengan rule set
+// aktif. Semua reference aktif tetap dibaca supaya total poin verified per
+// student (sebelum & sesudah) bisa dihitung utuh.
+func (s *PointRecalculationService) execute(ctx context.Context, job *models.PointRecalcJob) (int, int, error) {
+	set, err := s.PointRules.ActiveRuleSet()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.Repo.MarkRunning(job.ID, set.Version); err != nil {
+		return 0, 0, err
+	}
+
+	refs, err := s.RefRepo.GetAll()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var ids []string
+	for _, ref := range refs {
+		ids = append(ids, ref.MongoID)
+	}
+
+	docs := make(map[string]models.Achievement)
+	for start := 0; start < len(ids); start += recalcBatchSize {
+		end := min(start+recalcBatchSize, len(ids))
+
+		batch, err := s.AchievementRepo.FindByIDs(ctx, ids[start:end])
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, d := range batch {
+			docs[d.ID.Hex()] = d
+		}
+	}
+
+	membersMap, err := s.MemberRepo.GetByMongoIDs(ids)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	oldTotals := make(map[string]int)
+	newTotals := make(map[string]int)
+	credit := func(ref models.AchievementReference, doc models.Achievement, oldPoints, newPoints int) {
+		members := membersMap[ref.MongoID]
+		if len(members) == 0 {
+			oldTotals[ref.StudentID] += oldPoints
+			newTotals[ref.StudentID] += newPoints
+			return
+		}
+		for _, m := range members {
+			oldTotals[m.StudentID] += memberPoints(oldPoints, doc.PointRule, len(members))
+			newTotals[m.StudentID] += memberPoints(newPoints, doc.PointRule, len(members))
+		}
+	}
+
+	var items []models.PointRecalcItem
+	changed := 0
+
+	for _, ref := range refs {
+		doc, ok := docs[ref.MongoID]
+		if !ok {
+			continue
+		}
+
+		newPoints := doc.Points
+
+		if recalcInScope(job, ref, doc) {
+			eval := evaluatePoints(set, doc.AchievementType, doc.Details, doc.Tags)
+			newPoints = eval.Points
+
+			item := models.PointRecalcItem{
+				MongoID:        ref.MongoID,
+				StudentID:      ref.StudentID,
+				Status:         ref.Status,
+				OldPoints:      doc.Points,
+				NewPoints:      eval.Points,
+				OldRuleVersion: doc.PointRuleVersion,
+				NewRuleVersion: eval.RuleSetVersion,
+			}
+
+			if item.OldPoints != item.NewPoints {
+				changed++
+			}
+
+			if job.Mode == models.RecalcModeApply &&
+				(item.OldPoints != item.NewPoints || item.OldRuleVersion != item.NewRuleVersion) {
+				if err := s.AchievementRepo.UpdatePoints(ctx, ref.MongoID, eval.Points, eval.RuleSetVersion); err != nil {
+					// audit achievement yang sudah diubah tetap disimpan
+					// (item yang gagal ikut dicatat dengan applied = false)
+					items = append(items, item)
+					if ierr := s.Repo.InsertItems(job.ID, items); ierr != nil {
+						log.Println("recalc job", job.ID, "failed to save audit items:", ierr)
+					}
+					return len(items), changed, err
+				}
+				item.Applied = true
+			}
+
+			items = append(items, item)
+		}
+
+		if ref.Status == "verified" {
+			credit(ref, doc, doc.Points, newPoints)
+		}
+	}
+
+	if err := s.Repo.InsertItems(job.ID, items); err != nil {
+		return len(items), changed, err
+	}
+
+	var totals []models.PointRecalcStudentTotal
+	for studentID, oldTotal := range oldTotals {
+		if newTotals[studentID] == oldTotal {
+			continue
+		}
+		totals = append(totals, models.PointRecalcStudentTotal{
+			StudentID: studentID,
+			OldTotal:  oldTotal,
+			NewTotal:  newTotals[studentID],
+		})
+	}
+
+	if err := s.Repo.InsertStudentTotals(job.ID, totals); err != nil {
+		return len(items), changed, err
+	}
+
+	return len(items), changed, nil
+}
+
+func recalcInScope(job *models.PointRecalcJob, ref models.AchievementReference, doc models.Achievement) bool {
+	switch job.Scope {
+	case models.RecalcScopeType:
+		return doc.AchievementType == job.ScopeValue
+	case models.RecalcScopeStatus:
+		return ref.Status == job.ScopeValue
+	default:
+		return true
+	}
+}
+
+// ================= HANDLERS =================
+
+// StartPointRecalculation godoc
+// @Summary Start point recalculation job
+// @Description Recalculate stored points under the active rule set for all achievements, one type or one status. Use preview to only see the effect on student totals.
+// @Tags Point Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.PointRecalcRequest true "Recalculation scope"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /point-rules/recalculations [post]
+func (s *PointRecalculationService) Start(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	var req models.PointRecalcRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	if req.Scope == "" || req.Scope == models.RecalcScopeAll {
+		req.Scope = models.RecalcScopeAll
+		req.ScopeValue = ""
+	}
+
+	mode := models.RecalcModeApply
+	if req.Preview {
+		mode = models.RecalcModePreview
+	}
+
+	job := &models.PointRecalcJob{
+		Scope:      req.Scope,
+		ScopeValue: req.ScopeValue,
+		Mode:       mode,
+		CreatedBy:  &userID,
+	}
+
+	if err := s.start(job); err != nil {
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(202).JSON(fiber.Map{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// ListPointRecalculations godoc
+// @Summary List point recalculation jobs
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /point-rules/recalculations [get]
+func (s *PointRecalculationService) List(c *fiber.Ctx) error {
+	jobs, err := s.Repo.GetJobs()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    jobs,
+	})
+}
+
+// GetPointRecalculation godoc
+// @Summary Get point recalculation job
+// @Description Job status, old/new points per achievement and student total changes
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Param items query string false "changed (default) or all"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /point-rules/recalculations/{id} [get]
+func (s *PointRecalculationService) Get(c *fiber.Ctx) error {
+	job, err := s.Repo.GetJob(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "job not found"})
+	}
+
+	items, err := s.Repo.GetItems(job.ID, c.Query("items") != "all")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	totals, err := s.Repo.GetStudentTotals(job.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"job":            job,
+			"items":          items,
+			"student_totals": totals,
+		},
+	})
+}
+
+// ApplyPointRecalculation godoc
+// @Summary Apply a previewed recalculation
+// @Description Start an apply job with the same scope as a completed preview job
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Preview job ID"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /point-rules/recalculations/{id}/apply [post]
+func (s *PointRecalculationService) Apply(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	preview, err := s.Repo.GetJob(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "job not found"})
+	}
+
+	if preview.Mode != models.RecalcModePreview || preview.Status != models.RecalcStatusCompleted {
+		return c.Status(400).JSON(fiber.Map{"message": "only completed preview job can be applied"})
+	}
+
+	// rule set aktif berubah sejak preview → hasil apply bisa berbeda
+	set, err := s.PointRules.ActiveRuleSet()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+	if set.Version != preview.RuleSetVersion {
+		return c.Status(400).JSON(fiber.Map{"message": "active rule set changed since preview, run a new preview"})
+	}
+
+	job := &models.PointRecalcJob{
+		Scope:      preview.Scope,
+		ScopeValue: preview.ScopeValue,
+		Mode:       models.RecalcModeApply,
+		CreatedBy:  &userID,
+	}
+
+	if err := s.start(job); err != nil {
+		return c.Status(409).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(202).JSON(fiber.Map{
+		"success": true,
+		"data":    job,
+	})
+}
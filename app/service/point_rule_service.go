@@ -0,0 +1,368 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+	"pbluas/validation"
+)
+
+type PointRuleService struct {
+	Repo            *repository.PointRuleRepository
+	AchievementRepo *repository.AchievementRepository
+}
+
+func NewPointRuleService(
+	repo *repository.PointRuleRepository,
+	achievementRepo *repository.AchievementRepository,
+) *PointRuleService {
+	return &PointRuleService{
+		Repo:            repo,
+		AchievementRepo: achievementRepo,
+	}
+}
+
+// ================= EVALUATION =================
+
+// ActiveRuleSet mengembalikan rule set aktif dari database, atau rule set
+// bawaan (versi 0) jika admin belum pernah mengaktifkan rule set apa pun.
+func (s *PointRuleService) ActiveRuleSet() (*models.PointRuleSet, error) {
+	set, err := s.Repo.GetActive()
+	if errors.Is(err, sql.ErrNoRows) {
+		return defaultPointRuleSet(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Calculate menghitung poin achievement dengan rule set aktif
+func (s *PointRuleService) Calculate(achievementType string, details models.AchievementDetails, tags []string) (models.PointEvaluation, error) {
+	set, err := s.ActiveRuleSet()
+	if err != nil {
+		return models.PointEvaluation{}, err
+	}
+
+	return evaluatePoints(set, achievementType, details, tags), nil
+}
+
+// evaluatePoints: rule pertama (urut priority) yang semua kondisinya cocok.
+// Jika tidak ada yang cocok poinnya 0.
+func evaluatePoints(set *models.PointRuleSet, achievementType string, details models.AchievementDetails, tags []string) models.PointEvaluation {
+	result := models.PointEvaluation{
+		RuleSetID:      set.ID,
+		RuleSetVersion: set.Version,
+	}
+
+	rank := 0
+	if details.Rank != nil {
+		rank = int(*details.Rank)
+	}
+
+	for i := range set.Rules {
+		rule := &set.Rules[i]
+
+		if !matchText(rule.AchievementType, achievementType) ||
+			!matchText(rule.CompetitionLevel, details.CompetitionLevel) ||
+			!matchText(rule.MedalType, details.MedalType) {
+			continue
+		}
+		if rule.Rank != nil && *rule.Rank != rank {
+			continue
+		}
+		if rule.Tag != nil && !hasTag(tags, *rule.Tag) {
+			continue
+		}
+
+		result.Points = rule.Points
+		result.MatchedRule = rule
+		return result
+	}
+
+	return result
+}
+
+func matchText(cond *string, value string) bool {
+	return cond == nil || strings.EqualFold(*cond, strings.TrimSpace(value))
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultPointRuleSet: pedoman poin lama (sebelum rule disimpan di database)
+func defaultPointRuleSet() *models.PointRuleSet {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	competition := str("competition")
+	rules := []models.PointRule{
+		{AchievementType: competition, CompetitionLevel: str("international"), Rank: num(1), Points: 100},
+		{AchievementType: competition, CompetitionLevel: str("international"), Rank: num(2), Points: 80},
+		{AchievementType: competition, CompetitionLevel: str("international"), Rank: num(3), Points: 60},
+		{AchievementType: competition, CompetitionLevel: str("international"), Points: 40},
+		{AchievementType: competition, CompetitionLevel: str("national"), Rank: num(1), Points: 80},
+		{AchievementType: competition, CompetitionLevel: str("national"), Rank: num(2), Points: 60},
+		{AchievementType: competition, CompetitionLevel: str("national"), Rank: num(3), Points: 40},
+		{AchievementType: competition, CompetitionLevel: str("national"), Points: 20},
+		{AchievementType: competition, CompetitionLevel: str("regional"), Points: 10},
+		{AchievementType: competition, CompetitionLevel: str("local"), Points: 5},
+		{AchievementType: competition, Points: 10},
+		{AchievementType: str("publication"), Points: 40},
+		{AchievementType: str("certification"), Points: 20},
+		{Points: 10},
+	}
+
+	for i := range rules {
+		rules[i].Priority = (i + 1) * 10
+	}
+
+	return &models.PointRuleSet{
+		Version:     0,
+		Name:        "default",
+		Description: "built-in rule set, used until a rule set is activated",
+		IsActive:    true,
+		Rules:       rules,
+	}
+}
+
+// ================= HANDLERS =================
+
+// ListPointRuleSets godoc
+// @Summary List point rule sets
+// @Description List all versions of point rule sets (Admin)
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /point-rules [get]
+func (s *PointRuleService) List(c *fiber.Ctx) error {
+	list, err := s.Repo.GetAll()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    list,
+	})
+}
+
+// GetActivePointRuleSet godoc
+// @Summary Get active point rule set
+// @Description Get the rule set currently used to calculate points
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /point-rules/active [get]
+func (s *PointRuleService) GetActive(c *fiber.Ctx) error {
+	set, err := s.ActiveRuleSet()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    set,
+	})
+}
+
+// GetPointRuleSet godoc
+// @Summary Get point rule set
+// @Description Get point rule set with its rules
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule set ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /point-rules/{id} [get]
+func (s *PointRuleService) GetByID(c *fiber.Ctx) error {
+	set, err := s.Repo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "rule set not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    set,
+	})
+}
+
+// CreatePointRuleSet godoc
+// @Summary Create point rule set
+// @Description Create a new version of point rules. Rules are evaluated by priority, first match wins.
+// @Tags Point Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.PointRuleSetRequest true "Rule set payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /point-rules [post]
+func (s *PointRuleService) Create(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	var req models.PointRuleSetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	for i := range req.Rules {
+		// priority default mengikuti urutan di request
+		if req.Rules[i].Priority == 0 {
+			req.Rules[i].Priority = (i + 1) * 10
+		}
+	}
+
+	set := &models.PointRuleSet{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		CreatedBy:   &userID,
+		Rules:       req.Rules,
+	}
+
+	if err := s.Repo.Create(set); err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if req.Activate {
+		if err := s.Repo.Activate(set.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+		}
+		set.IsActive = true
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"data":    set,
+	})
+}
+
+// ActivatePointRuleSet godoc
+// @Summary Activate point rule set
+// @Description Make a rule set version the active one for new and updated achievements
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule set ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /point-rules/{id}/activate [post]
+func (s *PointRuleService) Activate(c *fiber.Ctx) error {
+	if err := s.Repo.Activate(c.Params("id")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(404).JSON(fiber.Map{"message": "rule set not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "rule set activated",
+	})
+}
+
+// DeletePointRuleSet godoc
+// @Summary Delete point rule set
+// @Description Delete a rule set that has never been activated
+// @Tags Point Rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule set ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /point-rules/{id} [delete]
+func (s *PointRuleService) Delete(c *fiber.Ctx) error {
+	if err := s.Repo.Delete(c.Params("id")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(400).JSON(fiber.Map{"message": "rule set not found or already activated"})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "rule set deleted",
+	})
+}
+
+// DryRunPoints godoc
+// @Summary Dry-run point calculation
+// @Description Show what an achievement (existing or from payload) would score under the active or given rule set
+// @Tags Point Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.PointDryRunRequest true "Dry-run payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /point-rules/dry-run [post]
+func (s *PointRuleService) DryRun(c *fiber.Ctx) error {
+	var req models.PointDryRunRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	// 1️⃣ rule set yang dipakai
+	var set *models.PointRuleSet
+	var err error
+	if req.RuleSetID != "" {
+		set, err = s.Repo.GetByID(req.RuleSetID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"message": "rule set not found"})
+		}
+	} else {
+		set, err = s.ActiveRuleSet()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+		}
+	}
+
+	// 2️⃣ achievement yang dinilai
+	response := fiber.Map{}
+	switch {
+	case req.AchievementID != "":
+		ach, err := s.AchievementRepo.FindByID(context.Background(), req.AchievementID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"message": "achievement not found"})
+		}
+		response["current_points"] = ach.Points
+		response["evaluation"] = evaluatePoints(set, ach.AchievementType, ach.Details, ach.Tags)
+
+	case req.Achievement != nil:
+		a := req.Achievement
+		response["evaluation"] = evaluatePoints(set, a.AchievementType, a.Details, a.Tags)
+
+	default:
+		return c.Status(400).JSON(fiber.Map{"message": "achievementId or achievement is required"})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
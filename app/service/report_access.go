@@ -0,0 +1,35 @@
+package service
+
+import "pbluas/app/models"
+
+// PermissionReportView: izin untuk laporan global (statistik, leaderboard,
+// perbandingan prodi). Admin selalu lolos lewat RBACMiddleware.
+const PermissionReportView = "report:view"
+
+// PermissionReportSchedule: izin mengelola laporan terjadwal
+const PermissionReportSchedule = "report:schedule"
+
+// advisorChecker: cukup IsAdvisorOfStudent dari AchievementReferenceRepository
+type advisorChecker interface {
+	IsAdvisorOfStudent(userID, studentID string) (bool, error)
+}
+
+// canAccessStudent: aturan akses laporan per mahasiswa, sama dengan detail
+// achievement. Mahasiswa hanya dirinya sendiri, dosen hanya mahasiswa
+// perwaliannya, admin semua.
+func canAccessStudent(refRepo advisorChecker, role, userID string, student *models.StudentDetail) bool {
+	switch role {
+	case "Mahasiswa":
+		return student.UserID == userID
+
+	case "Dosen", "Dosen Wali", "Lecturer":
+		allowed, err := refRepo.IsAdvisorOfStudent(userID, student.ID)
+		return err == nil && allowed
+
+	case "Admin":
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"pbluas/app/models"
+)
+
+// fakeAdvisors: daftar pasangan dosen (user_id) -> mahasiswa (students.id)
+type fakeAdvisors struct {
+	advisees map[string][]string
+	err      error
+}
+
+func (f fakeAdvisors) IsAdvisorOfStudent(userID, studentID string) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	for _, id := range f.advisees[userID] {
+		if id == studentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestCanAccessStudent(t *testing.T) {
+	student := &models.StudentDetail{ID: "student-1", UserID: "user-mhs-1"}
+	advisors := fakeAdvisors{advisees: map[string][]string{
+		"user-dosen-1": {"student-1"},
+		"user-dosen-2": {"student-2"},
+	}}
+
+	tests := []struct {
+		name    string
+		refRepo advisorChecker
+		role    string
+		userID  string
+		want    bool
+	}{
+		{"mahasiswa sendiri", advisors, "Mahasiswa", "user-mhs-1", true},
+		{"mahasiswa lain", advisors, "Mahasiswa", "user-mhs-2", false},
+		{"dosen wali perwalian", advisors, "Dosen Wali", "user-dosen-1", true},
+		{"dosen (alias lecturer) perwalian", advisors, "Lecturer", "user-dosen-1", true},
+		{"dosen bukan perwalian", advisors, "Dosen", "user-dosen-2", false},
+		{"dosen, query gagal", fakeAdvisors{err: errors.New("db down")}, "Dosen Wali", "user-dosen-1", false},
+		{"admin", advisors, "Admin", "user-admin", true},
+		{"role tidak dikenal", advisors, "Tamu", "user-mhs-1", false},
+		{"role kosong", advisors, "", "user-mhs-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canAccessStudent(tt.refRepo, tt.role, tt.userID, student); got != tt.want {
+				t.Errorf("canAccessStudent(%q, %q) = %v, want %v", tt.role, tt.userID, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"pbluas/app/models"
+)
+
+// ===== KOLOM EXPORT =====
+// Nama kolom sama dengan key JSON dan tidak boleh diganti,
+// karena dipakai template Excel di fakultas.
+
+var studentReportColumns = []string{
+	"student_id", "name", "program_study", "academic_year",
+	"achievement_id", "title", "type", "level", "detail",
+	"points", "status", "role", "revocation_reason",
+}
+
+func studentReportRows(report models.ReportStudentResponse) exportRows {
+	return func(emit func([]interface{}) error) error {
+		st := report.Student
+		for _, a := range report.Achievements {
+			err := emit([]interface{}{
+				st.StudentID, st.Name, st.ProgramStudy, st.AcademicYear,
+				a.ID, a.Title, a.Type, a.Level, a.Detail,
+				a.Points, a.Status, a.Role, a.RevocationReason,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Statistik berisi beberapa tabel, diratakan jadi satu tabel
+// section / key / name / value
+var statisticsColumns = []string{"section", "key", "name", "value"}
+
+func statisticsRows(stats models.ReportStatistics) exportRows {
+	return func(emit func([]interface{}) error) error {
+		if err := emit([]interface{}{"total", "", "", stats.Total}); err != nil {
+			return err
+		}
+
+		counts := []struct {
+			section string
+			values  map[string]int
+		}{
+			{"per_type", stats.PerType},
+			{"per_month", stats.PerMonth},
+			{"competition_levels", stats.CompetitionLevels},
+		}
+		for _, group := range counts {
+			for _, key := range sortedKeys(group.values) {
+				if err := emit([]interface{}{group.section, key, "", group.values[key]}); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, st := range stats.TopStudents {
+			if err := emit([]interface{}{"top_students", st.StudentID, st.Name, st.Points}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+var leaderboardColumns = []string{
+	"rank", "id", "student_id", "name", "program_study", "academic_year",
+	"points", "achievements",
+}
+
+func leaderboardRows(entries []models.LeaderboardEntry) exportRows {
+	return func(emit func([]interface{}) error) error {
+		for _, e := range entries {
+			err := emit([]interface{}{
+				e.Rank, e.ID, e.StudentID, e.Name, e.ProgramStudy, e.AcademicYear,
+				e.Points, e.Achievements,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+var programComparisonColumns = []string{
+	"program_study", "students", "active_students", "active_share",
+	"total_achievements", "total_points", "achievements_per_student", "points_per_student",
+}
+
+func programComparisonRows(programs []models.ProgramComparison) exportRows {
+	return func(emit func([]interface{}) error) error {
+		for _, p := range programs {
+			err := emit([]interface{}{
+				p.ProgramStudy, p.Students, p.ActiveStudents, p.ActiveShare,
+				p.TotalAchievements, p.TotalPoints, p.AchievementsPerStudent, p.PointsPerStudent,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var achievementListColumns = []string{
+	"id", "student_id", "achievement_type", "title", "status",
+	"points", "level", "detail", "event_date", "tags",
+	"member_count", "attachment_count", "possible_duplicate", "created_at",
+}
+
+// achievementListRows: possible_duplicate hanya diisi untuk verifikator,
+// sama seperti output JSON
+func achievementListRows(
+	achievements []models.Achievement,
+	statusMap map[string]string,
+	membersMap map[string][]models.AchievementMember,
+	showDuplicates bool,
+) exportRows {
+	return func(emit func([]interface{}) error) error {
+		for _, a := range achievements {
+			id := a.ID.Hex()
+
+			var duplicate interface{}
+			if showDuplicates {
+				duplicate = len(a.DuplicateFlags) > 0
+			}
+
+			err := emit([]interface{}{
+				id, a.StudentID, a.AchievementType, a.Title, statusMap[id],
+				a.Points, models.ReportLevel(a.AchievementType, a.Details),
+				models.DetailSummary(a.AchievementType, a.Details),
+				a.Details.EventDate, strings.Join(a.Tags, ", "),
+				len(membersMap[id]), len(a.Attachments), duplicate, a.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,666 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/robfig/cron/v3"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+	"pbluas/storage"
+	"pbluas/validation"
+)
+
+const (
+	// batas waktu satu laporan terjadwal
+	reportRunTimeout = 10 * time.Minute
+
+	defaultArchiveLimit = 20
+)
+
+// Nama file laporan per jenis (tanpa tanggal dan ekstensi)
+var reportFileNames = map[string]string{
+	models.ReportTypeStatistics:        "statistik-prestasi",
+	models.ReportTypeLeaderboard:       "leaderboard-prestasi",
+	models.ReportTypeProgramComparison: "perbandingan-prodi",
+	models.ReportTypeAccreditation:     "akreditasi",
+}
+
+var reportContentTypes = map[string]string{
+	FormatJSON: "application/json",
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ReportScheduleService menjalankan jadwal laporan (cron) dan menyimpan
+// hasilnya ke storage sebagai arsip. Laporan yang sudah jadi tidak pernah
+// dibuat ulang; jalankan jadwal lagi untuk data terbaru.
+type ReportScheduleService struct {
+	Repo    *repository.ReportScheduleRepository
+	Reports *ReportService
+	Storage storage.Storage
+
+	cron    *cron.Cron
+	mu      sync.Mutex
+	entries map[string]cron.EntryID // schedule id -> entry cron
+}
+
+func NewReportScheduleService(
+	repo *repository.ReportScheduleRepository,
+	reports *ReportService,
+	store storage.Storage,
+) *ReportScheduleService {
+	return &ReportScheduleService{
+		Repo:    repo,
+		Reports: reports,
+		Storage: store,
+		cron:    cron.New(),
+		entries: map[string]cron.EntryID{},
+	}
+}
+
+// ================= SCHEDULER =================
+
+// Start mendaftarkan semua jadwal aktif lalu menjalankan cron di background
+func (s *ReportScheduleService) Start() error {
+	schedules, err := s.Repo.GetAll(true)
+	if err != nil {
+		return err
+	}
+
+	for _, sch := range schedules {
+		if err := s.register(sch); err != nil {
+			// cron di database rusak tidak boleh menghentikan server
+			log.Println("report schedule", sch.ID, "not registered:", err)
+		}
+	}
+
+	s.cron.Start()
+	log.Printf("report scheduler started (%d schedules)", len(s.entries))
+	return nil
+}
+
+// register mengganti entry cron sebuah jadwal (atau menghapusnya jika nonaktif)
+func (s *ReportScheduleService) register(sch models.ReportSchedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.entries[sch.ID]; ok {
+		s.cron.Remove(id)
+		delete(s.entries, sch.ID)
+	}
+	if !sch.Enabled {
+		return nil
+	}
+
+	scheduleID := sch.ID
+	id, err := s.cron.AddFunc(sch.Cron, func() {
+		s.runScheduled(scheduleID, time.Now().Truncate(time.Minute))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.entries[sch.ID] = id
+	return nil
+}
+
+func (s *ReportScheduleService) unregister(scheduleID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.entries[scheduleID]; ok {
+		s.cron.Remove(id)
+		delete(s.entries, scheduleID)
+	}
+}
+
+// runScheduled dipanggil cron. Jadwal dibaca ulang dari database supaya
+// perubahan terakhir ikut terpakai, dan slot waktu diklaim di database
+// supaya tiap jadwal hanya berjalan sekali walau ada beberapa instance.
+func (s *ReportScheduleService) runScheduled(scheduleID string, slot time.Time) {
+	sch, err := s.Repo.GetByID(scheduleID)
+	if err != nil {
+		log.Println("report schedule", scheduleID, "not loaded:", err)
+		return
+	}
+	if !sch.Enabled {
+		return
+	}
+
+	run := &models.ReportRun{
+		ScheduleID:   &sch.ID,
+		ReportType:   sch.ReportType,
+		Filters:      resolvePeriod(sch.Period, sch.Filters, slot),
+		Format:       sch.Format,
+		Trigger:      models.ReportTriggerSchedule,
+		ScheduledFor: &slot,
+		CreatedBy:    sch.CreatedBy,
+	}
+
+	created, err := s.Repo.CreateRun(run)
+	if err != nil {
+		log.Println("report schedule", scheduleID, "run not created:", err)
+		return
+	}
+	if !created {
+		return // sudah dijalankan instance lain
+	}
+
+	s.execute(run)
+}
+
+// resolvePeriod mengganti from / to sesuai periode relatif jadwal
+func resolvePeriod(period string, filter models.StatisticsFilter, now time.Time) models.StatisticsFilter {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var from, to time.Time
+	switch period {
+	case models.ReportPeriodPreviousWeek:
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		from, to = monday.AddDate(0, 0, -7), monday.AddDate(0, 0, -1)
+	case models.ReportPeriodPreviousMonth:
+		first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		from, to = first.AddDate(0, -1, 0), first.AddDate(0, 0, -1)
+	case models.ReportPeriodAcademicYear:
+		from = time.Date(academicYearStart(now), time.August, 1, 0, 0, 0, 0, now.Location())
+		to = today
+	default:
+		return filter
+	}
+
+	filter.From = from.Format("2006-01-02")
+	filter.To = to.Format("2006-01-02")
+	return filter
+}
+
+// execute membuat file laporan dan menyimpannya ke storage
+func (s *ReportScheduleService) execute(run *models.ReportRun) {
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if err != nil {
+			if ferr := s.Repo.MarkFailed(run.ID, err); ferr != nil {
+				log.Println("report run", run.ID, "failed to finish:", ferr)
+			}
+		}
+	}()
+
+	if err = s.Repo.MarkRunning(run.ID); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportRunTimeout)
+	defer cancel()
+
+	data, name, err := s.generate(ctx, run)
+	if err != nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	run.Checksum = hex.EncodeToString(sum[:])
+	run.FileName = fmt.Sprintf("%s-%s.%s", name, run.CreatedAt.Format("20060102-1504"), run.Format)
+	run.ContentType = reportContentTypes[run.Format]
+	run.Size = int64(len(data))
+	run.StorageKey = fmt.Sprintf("reports/%s/%s_%s", run.CreatedAt.Format("2006/01"), run.ID, run.FileName)
+
+	if err = s.Storage.Put(ctx, run.StorageKey, bytes.NewReader(data), run.Size, run.ContentType); err != nil {
+		return
+	}
+
+	err = s.Repo.MarkCompleted(run)
+}
+
+// generate membuat isi laporan; nama file tanpa tanggal dan ekstensi
+func (s *ReportScheduleService) generate(ctx context.Context, run *models.ReportRun) ([]byte, string, error) {
+	name := reportFileNames[run.ReportType]
+
+	var data interface{}
+	var headers []string
+	var rows exportRows
+
+	switch run.ReportType {
+	case models.ReportTypeStatistics:
+		stats, err := s.Reports.buildStatistics(ctx, run.Filters)
+		if err != nil {
+			return nil, "", err
+		}
+		data, headers, rows = stats, statisticsColumns, statisticsRows(stats)
+
+	case models.ReportTypeLeaderboard:
+		entries, err := s.Reports.buildLeaderboard(ctx, run.Filters)
+		if err != nil {
+			return nil, "", err
+		}
+		data, headers, rows = entries, leaderboardColumns, leaderboardRows(entries)
+
+	case models.ReportTypeProgramComparison:
+		programs, err := s.Reports.buildProgramComparison(ctx, run.Filters)
+		if err != nil {
+			return nil, "", err
+		}
+		data, headers, rows = programs, programComparisonColumns, programComparisonRows(programs)
+
+	case models.ReportTypeAccreditation:
+		report, err := s.Reports.buildAccreditation(ctx, models.AccreditationQuery{
+			TS:           academicYearStart(run.CreatedAt),
+			ProgramStudy: run.Filters.ProgramStudy,
+			DateField:    run.Filters.DateField,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		name = fmt.Sprintf("%s-TS%d", name, report.TS)
+		if run.Format == FormatXLSX {
+			f, err := accreditationWorkbook(report)
+			if err != nil {
+				return nil, "", err
+			}
+			defer f.Close()
+
+			buf, err := f.WriteToBuffer()
+			if err != nil {
+				return nil, "", err
+			}
+			return buf.Bytes(), name, nil
+		}
+		data = report
+
+	default:
+		return nil, "", fmt.Errorf("unknown report type %q", run.ReportType)
+	}
+
+	if run.Format == FormatJSON {
+		out, err := json.Marshal(fiber.Map{
+			"report_type":  run.ReportType,
+			"generated_at": time.Now(),
+			"filters":      run.Filters,
+			"data":         data,
+		})
+		return out, name, err
+	}
+
+	if rows == nil {
+		return nil, "", fmt.Errorf("format %s not supported for %s", run.Format, run.ReportType)
+	}
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	var err error
+	if run.Format == FormatXLSX {
+		err = writeXLSX(w, headers, rows)
+	} else {
+		err = writeCSV(w, headers, rows)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), name, nil
+}
+
+// withNextRun mengisi next_run_at dari ekspresi cron
+func withNextRun(sch *models.ReportSchedule) {
+	if !sch.Enabled {
+		return
+	}
+	if spec, err := cron.ParseStandard(sch.Cron); err == nil {
+		next := spec.Next(time.Now())
+		sch.NextRunAt = &next
+	}
+}
+
+func scheduleFromRequest(sch *models.ReportSchedule, req models.ReportScheduleRequest) {
+	sch.Name = req.Name
+	sch.ReportType = req.ReportType
+	sch.Filters = req.Filters
+	sch.Period = req.Period
+	sch.Format = req.Format
+	sch.Cron = req.Cron
+	sch.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// ================= HANDLERS: SCHEDULE =================
+
+// CreateReportSchedule godoc
+// @Summary Create report schedule
+// @Description Define a report (statistics, leaderboard, program_comparison or accreditation) generated by a cron expression and stored in the report archive. period (previous_week, previous_month, academic_year) replaces filters.from/to on every run. Requires report:schedule.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body models.ReportScheduleRequest true "Schedule"
+// @Success 201 {object} models.ReportSchedule
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/schedules [post]
+func (s *ReportScheduleService) CreateSchedule(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	var req models.ReportScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	sch := &models.ReportSchedule{CreatedBy: &userID}
+	scheduleFromRequest(sch, req)
+
+	if err := s.Repo.Create(sch); err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+	if err := s.register(*sch); err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+	withNextRun(sch)
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"data":    sch,
+	})
+}
+
+// ListReportSchedules godoc
+// @Summary List report schedules
+// @Description Requires report:schedule.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /reports/schedules [get]
+func (s *ReportScheduleService) ListSchedules(c *fiber.Ctx) error {
+	schedules, err := s.Repo.GetAll(false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	for i := range schedules {
+		withNextRun(&schedules[i])
+	}
+	if schedules == nil {
+		schedules = []models.ReportSchedule{}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    schedules,
+	})
+}
+
+// GetReportSchedule godoc
+// @Summary Get report schedule
+// @Description Requires report:schedule.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.ReportSchedule
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /reports/schedules/{id} [get]
+func (s *ReportScheduleService) GetSchedule(c *fiber.Ctx) error {
+	sch, err := s.Repo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "schedule not found"})
+	}
+	withNextRun(sch)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sch,
+	})
+}
+
+// UpdateReportSchedule godoc
+// @Summary Update report schedule
+// @Description Replaces the schedule definition. Reports already in the archive are not changed. Requires report:schedule.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Param body body models.ReportScheduleRequest true "Schedule"
+// @Success 200 {object} models.ReportSchedule
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/schedules/{id} [put]
+func (s *ReportScheduleService) UpdateSchedule(c *fiber.Ctx) error {
+	sch, err := s.Repo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "schedule not found"})
+	}
+
+	var req models.ReportScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid request body"})
+	}
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	scheduleFromRequest(sch, req)
+
+	if err := s.Repo.Update(sch); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(404).JSON(fiber.Map{"message": "schedule not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+	if err := s.register(*sch); err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+	withNextRun(sch)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sch,
+	})
+}
+
+// DeleteReportSchedule godoc
+// @Summary Delete report schedule
+// @Description Stops the schedule. Its reports stay in the archive. Requires report:schedule.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /reports/schedules/{id} [delete]
+func (s *ReportScheduleService) DeleteSchedule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := s.Repo.Delete(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(404).JSON(fiber.Map{"message": "schedule not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+	s.unregister(id)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "schedule deleted",
+	})
+}
+
+// RunReportSchedule godoc
+// @Summary Run a report schedule now
+// @Description Generates the report in the background with the schedule's current definition; poll /reports/archive/{id} for the result. Requires report:schedule.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Success 202 {object} models.ReportRun
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /reports/schedules/{id}/run [post]
+func (s *ReportScheduleService) RunSchedule(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	userID := claims["id"].(string)
+
+	sch, err := s.Repo.GetByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "schedule not found"})
+	}
+
+	run := &models.ReportRun{
+		ScheduleID: &sch.ID,
+		ReportType: sch.ReportType,
+		Filters:    resolvePeriod(sch.Period, sch.Filters, time.Now()),
+		Format:     sch.Format,
+		Trigger:    models.ReportTriggerManual,
+		CreatedBy:  &userID,
+	}
+	if _, err := s.Repo.CreateRun(run); err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	go s.execute(run)
+
+	return c.Status(202).JSON(fiber.Map{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// ================= HANDLERS: ARCHIVE =================
+
+// ListReportArchive godoc
+// @Summary List archived reports
+// @Description Generated reports, newest first. Requires report:view.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param schedule_id query string false "Schedule ID"
+// @Param report_type query string false "statistics, leaderboard, program_comparison or accreditation"
+// @Param status query string false "pending, running, completed or failed"
+// @Param page query int false "Page (default 1)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/archive [get]
+func (s *ReportScheduleService) ListArchive(c *fiber.Ctx) error {
+	var q models.ReportRunQuery
+	var page models.Pagination
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid query parameters"})
+	}
+	if err := c.QueryParser(&page); err != nil {
+		return c.Status(400).JSON(fiber.Map{"message": "invalid query parameters"})
+	}
+	errs := append(validation.Struct(&q), validation.Struct(&page)...)
+	if errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	if page.Page == 0 {
+		page.Page = 1
+	}
+	if page.Limit == 0 {
+		page.Limit = defaultArchiveLimit
+	}
+
+	runs, total, err := s.Repo.GetRuns(q, page)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    runs,
+		"pagination": models.PageInfo{
+			Page:       page.Page,
+			Limit:      page.Limit,
+			Total:      total,
+			TotalPages: (total + page.Limit - 1) / page.Limit,
+		},
+	})
+}
+
+// GetReportArchive godoc
+// @Summary Get archived report metadata
+// @Description Status, filters used, file name, size and SHA-256 checksum. Requires report:view.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} models.ReportRun
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /reports/archive/{id} [get]
+func (s *ReportScheduleService) GetArchive(c *fiber.Ctx) error {
+	run, err := s.Repo.GetRun(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "report not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// DownloadReportArchive godoc
+// @Summary Download archived report
+// @Description The file exactly as generated; later data changes do not affect it. Requires report:view.
+// @Tags Reports
+// @Produce application/octet-stream
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /reports/archive/{id}/download [get]
+func (s *ReportScheduleService) DownloadArchive(c *fiber.Ctx) error {
+	run, err := s.Repo.GetRun(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"message": "report not found"})
+	}
+	if run.Status != models.ReportRunCompleted {
+		return c.Status(409).JSON(fiber.Map{"message": "report is " + run.Status})
+	}
+
+	r, info, err := s.Storage.Get(c.Context(), run.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"message": "report file not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, run.ContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+run.FileName+`"`)
+	c.Set(fiber.HeaderETag, `"`+run.Checksum+`"`)
+	return c.SendStream(r, int(info.Size))
+}
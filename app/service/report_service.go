@@ -1,56 +1,93 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"pbluas/app/models"
 	"pbluas/app/repository"
-	"sort"
+	"pbluas/validation"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type ReportService struct {
 	StudentRepo     repository.StudentRepository
 	RefRepo         *repository.AchievementReferenceRepository
 	AchievementRepo *repository.AchievementRepository
+	MemberRepo      *repository.AchievementMemberRepository
 }
 
 func NewReportService(
 	studentRepo repository.StudentRepository,
 	refRepo *repository.AchievementReferenceRepository,
 	achievementRepo *repository.AchievementRepository,
+	memberRepo *repository.AchievementMemberRepository,
 ) *ReportService {
 	return &ReportService{
 		StudentRepo:     studentRepo,
 		RefRepo:         refRepo,
 		AchievementRepo: achievementRepo,
+		MemberRepo:      memberRepo,
 	}
 }
 
 // GetStudentReport godoc
 // @Summary Get student achievement report
-// @Description Get detailed achievement report of a student
+// @Description Get detailed achievement report of a student. Mahasiswa: own report only, Dosen Wali: advisees only, Admin: all.
 // @Tags Reports
 // @Produce json
 // @Security BearerAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param id path string true "Student ID"
+// @Param format query string false "Output format: json (default), csv, xlsx"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Router /reports/student/{id} [get]
 func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
 	studentID := c.Params("id")
 
+	format, err := exportFormat(c)
+	if err != nil {
+		return exportFormatError(c, err)
+	}
+
 	// 1️⃣ Ambil student detail
 	student, err := s.StudentRepo.GetStudentByID(studentID)
 	if err != nil {
 		return fiber.NewError(404, "student not found")
 	}
 
+	// mahasiswa: laporan sendiri, dosen wali: mahasiswa perwalian, admin: semua
+	if !canAccessStudent(s.RefRepo, role, userID, student) {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "forbidden",
+		})
+	}
+
 	// 2️⃣ Ambil achievement references
 	refs, err := s.RefRepo.GetByStudentIDForReport(studentID)
 	if err != nil {
 		return fiber.NewError(500, "failed to load references")
 	}
 
+	// anggota tim untuk achievement beregu
+	var mongoIDs []string
+	for _, ref := range refs {
+		mongoIDs = append(mongoIDs, ref.MongoID)
+	}
+	membersMap, err := s.MemberRepo.GetByMongoIDs(mongoIDs)
+	if err != nil {
+		return fiber.NewError(500, "failed to load team members")
+	}
+
 	// 3️⃣ Siapkan response
 	var achievements []models.StudentAchievementDTO
 	totalPoints := 0
@@ -64,165 +101,156 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 			continue
 		}
 
-	level := ach.Details.CompetitionLevel
-	if level == "" {
-	level = "unknown"
-	}
+		level := models.ReportLevel(ach.AchievementType, ach.Details)
+
+		// poin untuk student ini (achievement beregu bisa dibagi)
+		members := membersMap[ref.MongoID]
+		points := memberPoints(ach.Points, ach.PointRule, len(members))
+		memberRole := ""
+		for _, m := range members {
+			if m.StudentID == studentID {
+				memberRole = m.Role
+			}
+		}
 
 		achievements = append(achievements, models.StudentAchievementDTO{
 			ID:     ref.ID,
 			Title:  ach.Title,
 			Type:   ach.AchievementType,
 			Level:  level,
-			Points: ach.Points,
+			Detail: models.DetailSummary(ach.AchievementType, ach.Details),
+			Points: points,
 			Status: ref.Status,
+			Role:   memberRole,
+
+			RevocationReason: ref.RevocationReason,
 		})
 
+		// achievement revoked / belum verified tidak menambah poin
 		if ref.Status == "verified" {
-			totalPoints += ach.Points
+			totalPoints += points
 		}
 
 		levelCount[level]++
 	}
 
+	report := models.ReportStudentResponse{
+		Student: models.StudentReportInfo{
+			ID:           student.ID,
+			StudentID:    student.StudentID,
+			Name:         student.FullName,
+			ProgramStudy: student.ProgramStudy,
+			AcademicYear: student.AcademicYear,
+		},
+		Achievements: achievements,
+		Summary: models.StudentSummary{
+			TotalAchievements: len(achievements),
+			TotalPoints:       totalPoints,
+			CompetitionLevels: levelCount,
+		},
+	}
+
 	// 4️⃣ Final response
+	if format != FormatJSON {
+		return sendExport(c, format, "laporan-"+student.StudentID, studentReportColumns, studentReportRows(report))
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
-		"data": models.ReportStudentResponse{
-			Student: models.StudentReportInfo{
-				ID:           student.ID,
-				StudentID:    student.StudentID,
-				Name:         student.FullName,
-				ProgramStudy: student.ProgramStudy,
-				AcademicYear: student.AcademicYear,
-			},
-			Achievements: achievements,
-			Summary: models.StudentSummary{
-				TotalAchievements: len(achievements),
-				TotalPoints:       totalPoints,
-				CompetitionLevels: levelCount,
-			},
-		},
+		"data":    report,
 	})
 }
 
 // GetStatistics godoc
 // @Summary Get achievement statistics
-// @Description Get global achievement statistics and analytics
+// @Description Achievement statistics for verified achievements. Every statistic (totals, per type, per month, levels, top students) respects the filters. Requires report:view.
 // @Tags Reports
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Security BearerAuth
+// @Param format query string false "Output format: json (default), csv, xlsx"
+// @Param from query string false "Period start (YYYY-MM-DD)"
+// @Param to query string false "Period end, inclusive (YYYY-MM-DD)"
+// @Param date_field query string false "Date used for the period: event (default) or verified"
+// @Param program_study query string false "Program study of the point holder"
+// @Param academic_year query string false "Cohort (academic year) of the point holder"
+// @Param advisor_id query string false "Lecturer ID of the point holder's advisor"
+// @Param type query string false "Achievement type"
+// @Param level query string false "Competition level: local, regional, national, international, unknown"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /reports/statistics [get]
 func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
-	refs, err := s.RefRepo.GetAll()
+	format, err := exportFormat(c)
 	if err != nil {
-		return fiber.NewError(500, "failed to load achievement references")
+		return exportFormatError(c, err)
 	}
 
-	// =========================
-	// 2️⃣ Siapkan variabel statistik
-	// =========================
-	total := 0
+	filter, errs, err := parseStatisticsQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid query parameters",
+		})
+	}
+	if errs != nil {
+		return validation.Respond(c, errs)
+	}
 
-	perType := map[string]int{}
-	perMonth := map[string]int{}
-	competitionLevels := map[string]int{}
-	studentPoints := map[string]int{} // studentID -> points
+	stats, err := s.buildStatistics(c.Context(), filter)
+	if err != nil {
+		return fiber.NewError(500, err.Error())
+	}
 
 	// =========================
-	// 3️⃣ Loop reference
+	// 3️⃣ RESPONSE
 	// =========================
-	for _, ref := range refs {
-
-		// HANYA HITUNG YANG VERIFIED
-		if ref.Status != "verified" {
-			continue
-		}
-
-		// Ambil detail achievement dari Mongo
-		ach, err := s.AchievementRepo.FindByID(c.Context(), ref.MongoID)
-		if err != nil {
-			continue
-		}
+	if format != FormatJSON {
+		return sendExport(c, format, "statistik-prestasi", statisticsColumns, statisticsRows(stats))
+	}
 
-		total++
-
-		// -------------------------
-		// TYPE
-		// -------------------------
-		perType[ach.AchievementType]++
-
-		// -------------------------
-		// PER BULAN (YYYY-MM)
-		// -------------------------
-		month := ach.CreatedAt.Format("2006-01")
-		perMonth[month]++
-
-		// -------------------------
-		// COMPETITION LEVEL
-		// -------------------------
-		level := "unknown"
-		if ach.Details.CompetitionLevel != "" {
-			level = ach.Details.CompetitionLevel
-		}
-		competitionLevels[level]++
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    stats,
+	})
+}
 
-		// -------------------------
-		// POINT MAHASISWA
-		// -------------------------
-		studentPoints[ref.StudentID] += ach.Points
+// buildStatistics: isi /reports/statistics, dipakai juga oleh laporan terjadwal
+func (s *ReportService) buildStatistics(ctx context.Context, filter models.StatisticsFilter) (models.ReportStatistics, error) {
+	// =========================
+	// 1️⃣ Penerima poin (SQL) + agregasi Mongo: type, bulan, tingkat, poin
+	// =========================
+	holders, agg, err := s.loadPointData(ctx, filter)
+	if err != nil {
+		return models.ReportStatistics{}, errors.New("failed to aggregate achievements")
 	}
 
 	// =========================
-	// 4️⃣ TOP MAHASISWA (SORT)
+	// 2️⃣ TOP MAHASISWA (urutan sama dengan leaderboard)
 	// =========================
-	type TopStudent struct {
-		StudentID string `json:"student_id"`
-		Name      string `json:"name"`
-		Points    int    `json:"points"`
+	ranked, err := s.rankStudents(holders, agg)
+	if err != nil {
+		return models.ReportStatistics{}, errors.New("failed to load students")
 	}
 
-	var topStudents []TopStudent
-
-	for studentID, points := range studentPoints {
-
-		student, err := s.StudentRepo.GetStudentByID(studentID)
-		if err != nil {
-			continue
-		}
-
-		topStudents = append(topStudents, TopStudent{
-			StudentID: studentID,
-			Name:      student.FullName,
-			Points:    points,
+	topStudents := []models.TopStudent{}
+	for _, e := range ranked[:min(topStudentsLimit, len(ranked))] {
+		topStudents = append(topStudents, models.TopStudent{
+			StudentID: e.ID,
+			Name:      e.Name,
+			Points:    e.Points,
 		})
 	}
 
-	// SORT DESC
-	sort.Slice(topStudents, func(i, j int) bool {
-		return topStudents[i].Points > topStudents[j].Points
-	})
-
-	// AMBIL MAX 5
-	if len(topStudents) > 5 {
-		topStudents = topStudents[:5]
-	}
-
-	// =========================
-	// 5️⃣ RESPONSE
-	// =========================
-	return c.JSON(fiber.Map{
-		"success": true,
-		"data": fiber.Map{
-			"total":              total,
-			"per_type":           perType,
-			"per_month":          perMonth,
-			"competition_levels": competitionLevels,
-			"top_students":       topStudents,
-		},
-	})
+	return models.ReportStatistics{
+		Total:             agg.Total,
+		PerType:           agg.PerType,
+		PerMonth:          agg.PerMonth,
+		CompetitionLevels: agg.CompetitionLevels,
+		TopStudents:       topStudents,
+		Filters:           filter,
+	}, nil
 }
-
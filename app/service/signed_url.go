@@ -0,0 +1,82 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ================= SIGNED URL =================
+// Link download dengan tanda tangan HMAC dan waktu kedaluwarsa, untuk
+// ditempel di laporan / email tanpa perlu token JWT.
+// Format: <path>?expires=<unix>&signature=<hex hmac-sha256(path|expires)>
+
+// batas lama berlaku link yang boleh diminta
+const (
+	defaultSignedURLTTL = 15 * time.Minute
+	maxSignedURLTTL     = 7 * 24 * time.Hour
+)
+
+// ErrSigningKeyMissing: SIGNED_URL_SECRET dan JWT_SECRET kosong. Key kosong
+// membuat tanda tangan bisa dipalsukan siapa saja, jadi link tidak dibuat
+// dan tidak diterima sama sekali.
+var ErrSigningKeyMissing = errors.New("signed links are not configured (set SIGNED_URL_SECRET or JWT_SECRET)")
+
+func signingKey() ([]byte, error) {
+	if key := os.Getenv("SIGNED_URL_SECRET"); key != "" {
+		return []byte(key), nil
+	}
+	if key := os.Getenv("JWT_SECRET"); key != "" {
+		return []byte(key), nil
+	}
+	return nil, ErrSigningKeyMissing
+}
+
+func signPath(path string, expires int64) (string, error) {
+	key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(path + "|" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// SignURL membuat URL bertanda tangan untuk path yang berlaku selama ttl.
+// PUBLIC_BASE_URL (opsional) ditambahkan di depan supaya link bisa dipakai
+// di luar aplikasi (email).
+func SignURL(path string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	expires := expiresAt.Unix()
+
+	signature, err := signPath(path, expires)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", signature)
+
+	return os.Getenv("PUBLIC_BASE_URL") + path + "?" + q.Encode(), expiresAt, nil
+}
+
+// VerifySignedURL mengecek tanda tangan dan waktu kedaluwarsa
+func VerifySignedURL(path, expiresParam, signature string) bool {
+	expires, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+
+	expected, err := signPath(path, expires)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// skpiData adalah isi dokumen SKPI satu mahasiswa
+type skpiData struct {
+	Number        string
+	StudentName   string
+	StudentNumber string
+	ProgramStudy  string
+	AcademicYear  string
+	Groups        []skpiGroup
+	TotalPoints   int
+	IssuedAt      time.Time
+}
+
+// skpiGroup: achievement verified per jenis (kategori)
+type skpiGroup struct {
+	LabelID string
+	LabelEN string
+	Items   []skpiItem
+	Points  int
+}
+
+type skpiItem struct {
+	Title  string
+	Detail string
+	Level  string
+	Date   string
+	Points int
+}
+
+func (d skpiData) totalAchievements() int {
+	n := 0
+	for _, g := range d.Groups {
+		n += len(g.Items)
+	}
+	return n
+}
+
+// tingkat achievement dalam dua bahasa
+var skpiLevels = map[string]string{
+	"local":         "Lokal / Local",
+	"regional":      "Regional / Regional",
+	"national":      "Nasional / National",
+	"international": "Internasional / International",
+}
+
+func skpiLevel(level string) string {
+	if label, ok := skpiLevels[level]; ok {
+		return label
+	}
+	if level == "" || level == "unknown" {
+		return "-"
+	}
+	return level
+}
+
+func formatDateEN(t time.Time) string {
+	return t.Format("2 January 2006")
+}
+
+// renderSKPIPDF membuat SKPI A4 portrait dua bahasa (Indonesia / Inggris)
+func renderSKPIPDF(d skpiData) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("SKPI "+d.Number, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageW, _ := pdf.GetPageSize()
+	width := pageW - 40
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-14)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(width/2, 5, tr("SKPI No. "+d.Number), "", 0, "L", false, 0, "")
+		pdf.CellFormat(width/2, 5, fmt.Sprintf("Halaman / Page %d", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+
+	// ===== JUDUL =====
+	pdf.SetTextColor(30, 60, 120)
+	pdf.SetFont("Helvetica", "B", 15)
+	pdf.CellFormat(width, 8, "SURAT KETERANGAN PENDAMPING IJAZAH", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "I", 12)
+	pdf.CellFormat(width, 6, "Diploma Supplement", "", 1, "C", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(width, 6, tr("Nomor / Number: "+d.Number), "", 1, "C", false, 0, "")
+	pdf.Ln(6)
+
+	section := func(id, en string) {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(230, 236, 245)
+		// bar latar penuh, lalu judul Inggris (italic) ditulis di atasnya
+		pdf.CellFormat(width, 7, tr(id+" / "), "", 0, "L", true, 0, "")
+		pdf.SetX(20 + pdf.GetStringWidth(tr(id+" / ")) + 1)
+		pdf.SetFont("Helvetica", "BI", 11)
+		pdf.CellFormat(0, 7, tr(en), "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+
+	// ===== 1. IDENTITAS =====
+	section("1. Informasi Identitas Pemegang SKPI", "Information Identifying the Holder")
+
+	identity := [][3]string{
+		{"Nama Lengkap", "Full Name", d.StudentName},
+		{"Nomor Induk Mahasiswa", "Student Identification Number", d.StudentNumber},
+		{"Program Studi", "Study Program", d.ProgramStudy},
+		{"Angkatan", "Year of Entry", d.AcademicYear},
+	}
+	for _, row := range identity {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(45, 5, tr(row[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(0, 5, tr(": "+row[2]), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(45, 4, tr(row[1]), "", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(1)
+	}
+	pdf.Ln(4)
+
+	// ===== 2. PRESTASI =====
+	section("2. Prestasi dan Penghargaan Non-Akademik", "Non-Academic Achievements and Awards")
+
+	cols := []struct {
+		id, en string
+		w      float64
+		align  string
+	}{
+		{"No", "No", 10, "C"},
+		{"Prestasi", "Achievement", 84, "L"},
+		{"Tingkat", "Level", 34, "C"},
+		{"Tanggal", "Date", 24, "C"},
+		{"Poin", "Points", 18, "C"},
+	}
+
+	tableHeader := func() {
+		pdf.SetFillColor(245, 245, 245)
+		pdf.SetFont("Helvetica", "B", 9)
+		x, y := pdf.GetXY()
+		for _, col := range cols {
+			pdf.Rect(x, y, col.w, 10, "FD")
+			pdf.SetXY(x, y+1)
+			pdf.CellFormat(col.w, 4, col.id, "", 0, "C", false, 0, "")
+			pdf.SetFont("Helvetica", "BI", 8)
+			pdf.SetXY(x, y+5)
+			pdf.CellFormat(col.w, 4, col.en, "", 0, "C", false, 0, "")
+			pdf.SetFont("Helvetica", "B", 9)
+			x += col.w
+		}
+		pdf.SetXY(20, y+10)
+	}
+
+	_, pageH := pdf.GetPageSize()
+	const lineH = 4.5
+
+	if len(d.Groups) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.MultiCell(width, 5, tr("Belum ada prestasi terverifikasi. / No verified achievements recorded."), "", "L", false)
+	}
+
+	no := 0
+	for _, g := range d.Groups {
+		// judul kategori jangan terpisah dari baris pertamanya
+		if pdf.GetY()+30 > pageH-20 {
+			pdf.AddPage()
+		}
+
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(pdf.GetStringWidth(tr(g.LabelID+" / "))+1, 7, tr(g.LabelID+" / "), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "BI", 10)
+		pdf.CellFormat(0, 7, tr(g.LabelEN), "", 1, "L", false, 0, "")
+		tableHeader()
+
+		for _, item := range g.Items {
+			no++
+
+			text := item.Title
+			if item.Detail != "" && item.Detail != item.Title {
+				text += "\n" + item.Detail
+			}
+			pdf.SetFont("Helvetica", "", 9)
+			lines := pdf.SplitLines([]byte(tr(text)), cols[1].w-2)
+			levelLines := pdf.SplitLines([]byte(tr(skpiLevel(item.Level))), cols[2].w-2)
+			n := len(lines)
+			if len(levelLines) > n {
+				n = len(levelLines)
+			}
+			h := float64(n)*lineH + 2
+
+			if pdf.GetY()+h > pageH-20 {
+				pdf.AddPage()
+				tableHeader()
+			}
+
+			x, y := pdf.GetXY()
+			values := []string{
+				fmt.Sprint(no),
+				"",
+				"",
+				item.Date,
+				fmt.Sprint(item.Points),
+			}
+			for i, col := range cols {
+				pdf.Rect(x, y, col.w, h, "D")
+				switch i {
+				case 1:
+					for j, line := range lines {
+						pdf.SetXY(x+1, y+1+float64(j)*lineH)
+						pdf.CellFormat(col.w-2, lineH, string(line), "", 0, "L", false, 0, "")
+					}
+				case 2:
+					for j, line := range levelLines {
+						pdf.SetXY(x+1, y+1+float64(j)*lineH)
+						pdf.CellFormat(col.w-2, lineH, string(line), "", 0, "C", false, 0, "")
+					}
+				default:
+					pdf.SetXY(x, y+1)
+					pdf.CellFormat(col.w, lineH, values[i], "", 0, col.align, false, 0, "")
+				}
+				x += col.w
+			}
+			pdf.SetXY(20, y+h)
+		}
+
+		// subtotal kategori
+		pdf.SetFont("Helvetica", "B", 9)
+		labelW := width - cols[len(cols)-1].w
+		pdf.CellFormat(labelW, 6, tr("Jumlah poin / Subtotal points"), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(cols[len(cols)-1].w, 6, fmt.Sprint(g.Points), "1", 1, "C", false, 0, "")
+		pdf.Ln(4)
+	}
+
+	// ===== 3. RINGKASAN =====
+	if pdf.GetY()+70 > pageH-20 {
+		pdf.AddPage()
+	}
+	section("3. Ringkasan", "Summary")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(80, 6, tr("Jumlah prestasi / Total achievements"), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf(": %d", d.totalAchievements()), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(80, 6, tr("Total poin / Total points"), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf(": %d", d.TotalPoints), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.Ln(2)
+	pdf.MultiCell(width, 4, tr(strings.Join([]string{
+		"Dokumen ini hanya memuat prestasi yang telah diverifikasi oleh dosen wali.",
+		"This document lists only achievements verified by the academic advisor.",
+	}, "\n")), "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(8)
+
+	// ===== PENGESAHAN =====
+	signX := 20 + width - 80
+	pdf.SetX(signX)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(80, 5, tr("Diterbitkan / Issued: "+formatDateID(d.IssuedAt)), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.CellFormat(80, 5, "("+formatDateEN(d.IssuedAt)+")", "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(80, 5, "Wakil Dekan Bidang Kemahasiswaan", "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.CellFormat(80, 5, "Vice Dean for Student Affairs", "", 2, "L", false, 0, "")
+	pdf.Ln(20)
+	pdf.SetX(signX)
+	pdf.CellFormat(70, 5, "", "B", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
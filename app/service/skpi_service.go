@@ -0,0 +1,353 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+	"pbluas/validation"
+)
+
+type SKPIService struct {
+	Repo            *repository.SKPIRepository
+	StudentRepo     repository.StudentRepository
+	RefRepo         *repository.AchievementReferenceRepository
+	AchievementRepo *repository.AchievementRepository
+	MemberRepo      *repository.AchievementMemberRepository
+}
+
+func NewSKPIService(
+	repo *repository.SKPIRepository,
+	studentRepo repository.StudentRepository,
+	refRepo *repository.AchievementReferenceRepository,
+	achievementRepo *repository.AchievementRepository,
+	memberRepo *repository.AchievementMemberRepository,
+) *SKPIService {
+	return &SKPIService{
+		Repo:            repo,
+		StudentRepo:     studentRepo,
+		RefRepo:         refRepo,
+		AchievementRepo: achievementRepo,
+		MemberRepo:      memberRepo,
+	}
+}
+
+// buildSKPI mengumpulkan achievement verified mahasiswa (termasuk achievement
+// beregu), dikelompokkan per jenis dengan urutan sesuai models.AchievementTypes
+func (s *SKPIService) buildSKPI(ctx context.Context, student *models.StudentDetail, issuedBy string) (skpiData, error) {
+	refs, err := s.RefRepo.GetByStudentIDForReport(student.ID)
+	if err != nil {
+		return skpiData{}, err
+	}
+
+	var mongoIDs []string
+	for _, ref := range refs {
+		if ref.Status == "verified" {
+			mongoIDs = append(mongoIDs, ref.MongoID)
+		}
+	}
+
+	achievements, err := s.AchievementRepo.FindByIDs(ctx, mongoIDs)
+	if err != nil {
+		return skpiData{}, err
+	}
+	membersMap, err := s.MemberRepo.GetByMongoIDs(mongoIDs)
+	if err != nil {
+		return skpiData{}, err
+	}
+
+	doc, err := s.Repo.GetOrCreate(student.ID, issuedBy)
+	if err != nil {
+		return skpiData{}, err
+	}
+
+	data := skpiData{
+		Number:        doc.Number,
+		StudentName:   student.FullName,
+		StudentNumber: student.StudentID,
+		ProgramStudy:  student.ProgramStudy,
+		AcademicYear:  student.AcademicYear,
+		IssuedAt:      doc.IssuedAt, // tanggal terbit pertama, sama seperti nomornya
+	}
+
+	groups := map[string]*skpiGroup{}
+	for _, a := range achievements {
+		g, ok := groups[a.AchievementType]
+		if !ok {
+			g = &skpiGroup{LabelID: "Lainnya", LabelEN: "Other"}
+			if spec := models.GetAchievementTypeSpec(a.AchievementType); spec != nil {
+				g.LabelID, g.LabelEN = spec.Label, spec.LabelEN
+			}
+			groups[a.AchievementType] = g
+		}
+
+		points := memberPoints(a.Points, a.PointRule, len(membersMap[a.ID.Hex()]))
+		g.Items = append(g.Items, skpiItem{
+			Title:  a.Title,
+			Detail: models.DetailSummary(a.AchievementType, a.Details),
+			Level:  models.ReportLevel(a.AchievementType, a.Details),
+			Date:   models.EventDate(a.Details),
+			Points: points,
+		})
+		g.Points += points
+		data.TotalPoints += points
+	}
+
+	var order []string
+	for _, spec := range models.AchievementTypes {
+		order = append(order, spec.Type)
+	}
+	for t := range groups {
+		if models.GetAchievementTypeSpec(t) == nil {
+			order = append(order, t)
+		}
+	}
+
+	for _, t := range order {
+		g, ok := groups[t]
+		if !ok {
+			continue
+		}
+		sort.SliceStable(g.Items, func(i, j int) bool {
+			return g.Items[i].Date < g.Items[j].Date
+		})
+		data.Groups = append(data.Groups, *g)
+	}
+
+	return data, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func skpiFileName(student *models.StudentDetail) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(student.FullName, "_"), "_")
+	return "SKPI-" + unsafeFileChars.ReplaceAllString(student.StudentID, "_") + "-" + name + ".pdf"
+}
+
+// DownloadSKPI godoc
+// @Summary Download student SKPI
+// @Description Bilingual (Indonesian/English) Diploma Supplement PDF listing verified achievements grouped by category, with point totals and a document number. Mahasiswa: own only, Dosen Wali: advisees, Admin: all.
+// @Tags Reports
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path string true "Student ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /reports/student/{id}/skpi [get]
+func (s *SKPIService) Download(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	student, err := s.StudentRepo.GetStudentByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{
+			"message": "student not found",
+		})
+	}
+
+	if !canAccessStudent(s.RefRepo, role, userID, student) {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "forbidden",
+		})
+	}
+
+	data, err := s.buildSKPI(c.Context(), student, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	pdf, err := renderSKPIPDF(data)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+skpiFileName(student)+`"`)
+	return c.Send(pdf)
+}
+
+// BatchSKPI godoc
+// @Summary Generate SKPI for a cohort (Admin)
+// @Description Generates SKPI PDFs for a whole cohort (academicYear, optionally narrowed by programStudy) or an explicit list of graduating students, returned as a ZIP with a daftar-skpi.csv manifest.
+// @Tags Reports
+// @Accept json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param body body models.SKPIBatchRequest true "Cohort or student list"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /reports/skpi/batch [post]
+func (s *SKPIService) Batch(c *fiber.Ctx) error {
+	claims := c.Locals("user_claims").(jwt.MapClaims)
+	role := claims["role"].(string)
+	userID := claims["id"].(string)
+
+	if role != "Admin" {
+		return c.Status(403).JSON(fiber.Map{
+			"message": "only admin can generate SKPI in batch",
+		})
+	}
+
+	var req models.SKPIBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"message": "invalid request body",
+		})
+	}
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
+	// 1️⃣ tentukan daftar mahasiswa sebelum response mulai dikirim
+	students, status, err := s.batchStudents(req)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	// 2️⃣ stream ZIP, satu PDF per mahasiswa
+	filename := "SKPI-" + time.Now().Format("20060102")
+	if req.AcademicYear != "" {
+		filename = "SKPI-" + unsafeFileChars.ReplaceAllString(req.AcademicYear, "_") + "-" + time.Now().Format("20060102")
+	}
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`.zip"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := s.writeBatchZip(context.Background(), w, students, userID); err != nil {
+			log.Println("skpi batch", filename, "failed:", err)
+		}
+	})
+
+	return nil
+}
+
+func (s *SKPIService) batchStudents(req models.SKPIBatchRequest) ([]models.StudentDetail, int, error) {
+	if len(req.StudentIDs) > 0 {
+		var students []models.StudentDetail
+		var missing []string
+		for _, id := range req.StudentIDs {
+			student, err := s.StudentRepo.GetStudentByID(id)
+			if err != nil {
+				missing = append(missing, id)
+				continue
+			}
+			students = append(students, *student)
+		}
+		if len(missing) > 0 {
+			return nil, 404, fmt.Errorf("student not found: %s", strings.Join(missing, ", "))
+		}
+		return students, 0, nil
+	}
+
+	all, err := s.StudentRepo.GetAllStudents()
+	if err != nil {
+		return nil, 500, err
+	}
+
+	var students []models.StudentDetail
+	for _, st := range all {
+		if st.AcademicYear != strings.TrimSpace(req.AcademicYear) {
+			continue
+		}
+		if req.ProgramStudy != "" && !strings.EqualFold(st.ProgramStudy, strings.TrimSpace(req.ProgramStudy)) {
+			continue
+		}
+		students = append(students, st)
+	}
+	if len(students) == 0 {
+		return nil, 404, fmt.Errorf("no students found for this cohort")
+	}
+
+	sort.Slice(students, func(i, j int) bool {
+		return students[i].StudentID < students[j].StudentID
+	})
+	return students, 0, nil
+}
+
+// writeBatchZip: mahasiswa yang gagal dibuatkan PDF tetap dicatat
+// di daftar-skpi.csv supaya admin tahu siapa yang perlu diulang
+func (s *SKPIService) writeBatchZip(ctx context.Context, w *bufio.Writer, students []models.StudentDetail, issuedBy string) error {
+	zw := zip.NewWriter(w)
+
+	type manifestRow struct {
+		nim, name, number, file, status string
+		total, points                   int
+	}
+	var manifest []manifestRow
+
+	for i := range students {
+		student := &students[i]
+		row := manifestRow{nim: student.StudentID, name: student.FullName, status: "ok"}
+
+		data, err := s.buildSKPI(ctx, student, issuedBy)
+		var pdf []byte
+		if err == nil {
+			pdf, err = renderSKPIPDF(data)
+		}
+		if err != nil {
+			row.status = "error: " + err.Error()
+			manifest = append(manifest, row)
+			continue
+		}
+
+		row.number = data.Number
+		row.file = skpiFileName(student)
+		row.total = data.totalAchievements()
+		row.points = data.TotalPoints
+
+		f, err := zw.Create(row.file)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(pdf); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err // client memutus koneksi
+		}
+
+		manifest = append(manifest, row)
+	}
+
+	f, err := zw.Create("daftar-skpi.csv")
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	_ = cw.Write([]string{"student_id", "name", "number", "file", "total_achievements", "total_points", "status"})
+	for _, row := range manifest {
+		_ = cw.Write([]string{
+			row.nim, row.name, row.number, row.file,
+			fmt.Sprint(row.total), fmt.Sprint(row.points), row.status,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"pbluas/app/models"
 	"pbluas/app/repository"
+	"pbluas/validation"
 )
 
 type StudentService struct {
@@ -16,7 +17,7 @@ type StudentService struct {
 }
 
 type AssignAdvisorRequest struct {
-	LecturerID string `json:"lecturer_id" validate:"required"`
+	LecturerID string `json:"lecturer_id" validate:"required,uuid"`
 }
 
 func NewStudentService(
@@ -140,6 +141,7 @@ func (s *StudentService) GetStudentByID(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /students/{id}/advisor [put]
 func (s *StudentService) AssignAdvisor(c *fiber.Ctx) error {
 	studentID := c.Params("id")
@@ -149,6 +151,10 @@ func (s *StudentService) AssignAdvisor(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"message": "invalid body"})
 	}
 
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
 	// cek student ada
 	_, err := s.StudentRepo.GetStudentByID(studentID)
 	if err != nil {
@@ -266,6 +272,8 @@ func (s *StudentService) GetStudentAchievements(c *fiber.Ctx) error {
 			"verifiedAt":      ref.VerifiedAt,
 			"verifiedBy":      ref.VerifiedBy,
 			"rejectionNote":   ref.RejectionNote,
+			"revokedAt":       ref.RevokedAt,
+			"revocationReason": ref.RevocationReason,
 			"createdAt":       a.CreatedAt,
 		})
 	}
@@ -13,6 +13,7 @@ import (
 	"pbluas/app/models"
 	"pbluas/app/repository"
 	"pbluas/config"
+	"pbluas/validation"
 )
 
 type UserService struct {
@@ -28,8 +29,8 @@ func NewUserService(repo repository.UserRepository, perm *repository.PermissionR
 }
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"notblank"`
+	Password string `json:"password" validate:"required"`
 }
 
 // Login godoc
@@ -41,6 +42,7 @@ type LoginRequest struct {
 // @Param body body LoginRequest true "Login payload"
 // @Success 200 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /auth/login [post]
 func (s *UserService) Login(c *fiber.Ctx) error {
 	var req LoginRequest
@@ -56,6 +58,12 @@ func (s *UserService) Login(c *fiber.Ctx) error {
 		})
 	}
 
+	// 422 – Field kosong
+
+	if errs := validation.Struct(&req); errs != nil {
+		return validation.Respond(c, errs)
+	}
+
 
 	// 401 – Username not found
 	
@@ -385,6 +393,7 @@ func (s *UserService) GetUserByID(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /users [post]
 func (s *UserService) CreateUser(c *fiber.Ctx) error {
     var req models.CreateUserRequest
@@ -394,6 +403,10 @@ func (s *UserService) CreateUser(c *fiber.Ctx) error {
         })
     }
 
+    if errs := validation.Struct(&req); errs != nil {
+        return validation.Respond(c, errs)
+    }
+
     // Hash password
     hashed, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 
@@ -431,6 +444,7 @@ func (s *UserService) CreateUser(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /users/{id} [put]
 func (s *UserService) UpdateUser(c *fiber.Ctx) error {
     id := c.Params("id")
@@ -449,6 +463,10 @@ func (s *UserService) UpdateUser(c *fiber.Ctx) error {
         })
     }
 
+    if errs := validation.Struct(&req); errs != nil {
+        return validation.Respond(c, errs)
+    }
+
     // Update fields
     if req.Email != "" {
         user.Email = req.Email
@@ -519,6 +537,7 @@ func (s *UserService) DeleteUser(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Router /users/{id}/role [put]
 func (s *UserService) UpdateUserRole(c *fiber.Ctx) error {
     id := c.Params("id")
@@ -530,6 +549,10 @@ func (s *UserService) UpdateUserRole(c *fiber.Ctx) error {
         })
     }
 
+    if errs := validation.Struct(&req); errs != nil {
+        return validation.Respond(c, errs)
+    }
+
     if err := s.Repo.UpdateUserRole(id, req.RoleID); err != nil {
         return c.Status(500).JSON(fiber.Map{
             "message": "Failed to update role",
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"fmt"
+
+	"pbluas/app/repository"
+	"pbluas/app/service"
+)
+
+// Services berisi dependency yang dibutuhkan subcommand CLI
+type Services struct {
+	Consistency     *service.ConsistencyService
+	AchievementRepo *repository.AchievementRepository
+	Achievement     *service.AchievementService
+}
+
+// Run menjalankan subcommand dari os.Args[1:].
+// Dipanggil dari main sebelum server HTTP dijalankan.
+func Run(args []string, svc Services) error {
+	switch args[0] {
+	case "consistency":
+		return runConsistency(args[1:], svc.Consistency)
+	case "storage":
+		return runStorage(args[1:], svc.AchievementRepo)
+	case "scan":
+		return runScan(args[1:], svc.Achievement)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"pbluas/app/service"
+)
+
+// runConsistency:
+//
+//	pbluas consistency check [-json]
+//	pbluas consistency repair [-apply] [-json]
+//
+// repair tanpa -apply hanya menampilkan rencana perbaikan (dry run).
+func runConsistency(args []string, svc *service.ConsistencyService) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: consistency <check|repair> [-apply] [-json]")
+	}
+
+	mode := args[0]
+	if mode != "check" && mode != "repair" {
+		return fmt.Errorf("unknown consistency mode %q", mode)
+	}
+
+	fs := flag.NewFlagSet("consistency "+mode, flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "apply repairs (default: dry run)")
+	asJSON := fs.Bool("json", false, "print report as JSON")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	report, err := svc.Check(ctx)
+	if err != nil {
+		return err
+	}
+
+	if mode == "repair" && *apply {
+		svc.Repair(ctx, report)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	printConsistencyReport(report, mode == "repair")
+	return nil
+}
+
+func printConsistencyReport(report *service.ConsistencyReport, showActions bool) {
+	fmt.Printf("checked %d references, %d mongo documents\n\n",
+		report.CheckedReferences, report.CheckedDocuments)
+
+	for _, category := range service.ConsistencyCategories {
+		fmt.Printf("%-20s %d\n", category, report.Counts[category])
+
+		for _, issue := range report.Issues {
+			if issue.Category != category {
+				continue
+			}
+
+			fmt.Printf("  ref=%s mongo=%s student=%s", issue.ReferenceID, issue.MongoID, issue.StudentID)
+			if issue.MongoStudentID != "" {
+				fmt.Printf(" mongo_student=%s", issue.MongoStudentID)
+			}
+			fmt.Println()
+
+			if !showActions {
+				continue
+			}
+
+			switch {
+			case issue.Action == "":
+				fmt.Println("    -> no automatic repair")
+			case issue.Error != "":
+				fmt.Printf("    -> FAILED %s: %s\n", issue.Action, issue.Error)
+			case issue.Repaired:
+				fmt.Printf("    -> repaired: %s\n", issue.Action)
+			default:
+				fmt.Printf("    -> would %s\n", issue.Action)
+			}
+		}
+	}
+
+	if showActions && report.DryRun {
+		fmt.Println("\ndry run: nothing changed, re-run with -apply to repair")
+	}
+}
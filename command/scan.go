@@ -0,0 +1,35 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"pbluas/app/service"
+)
+
+// runScan:
+//
+//	pbluas scan
+//
+// Men-scan semua attachment yang masih pending atau belum pernah discan
+// (attachment lama, atau scan background gagal karena clamd tidak tersedia).
+func runScan(args []string, svc *service.AchievementService) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: scan")
+	}
+
+	fmt.Printf("scanning pending attachments with %s scanner\n", svc.Scanner.Name())
+
+	summary, err := svc.ScanPending(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("scanned %d: clean %d, infected %d, failed %d\n",
+		summary.Scanned, summary.Clean, summary.Infected, summary.Failed)
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d attachments could not be scanned", summary.Failed)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"pbluas/app/repository"
+	"pbluas/storage"
+)
+
+// runStorage:
+//
+//	pbluas storage migrate -from local -to s3 [-apply] [-delete-source]
+//
+// Menyalin semua file attachment yang tercatat di Mongo (beserta thumbnail
+// preview-nya) dari satu backend ke backend lain. Key tetap sama, jadi
+// metadata tidak perlu diubah.
+// Tanpa -apply hanya menampilkan file yang akan dipindahkan (dry run).
+func runStorage(args []string, achievementRepo *repository.AchievementRepository) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return fmt.Errorf("usage: storage migrate -from <driver> -to <driver> [-apply] [-delete-source]")
+	}
+
+	fs := flag.NewFlagSet("storage migrate", flag.ContinueOnError)
+	from := fs.String("from", storage.DriverLocal, "source storage driver")
+	to := fs.String("to", storage.DriverS3, "destination storage driver")
+	apply := fs.Bool("apply", false, "copy files (default: dry run)")
+	deleteSource := fs.Bool("delete-source", false, "delete file from source after successful copy")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *from == *to {
+		return fmt.Errorf("-from and -to must be different drivers")
+	}
+
+	src, err := storage.New(*from)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	dst, err := storage.New(*to)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+
+	ctx := context.Background()
+
+	docs, err := achievementRepo.ListAttachments(ctx)
+	if err != nil {
+		return err
+	}
+
+	var copied, skipped, missing, failed int
+	for _, doc := range docs {
+		var keys []string
+		for _, att := range doc.Attachments {
+			keys = append(keys, att.Key())
+			if att.ThumbnailKey != "" {
+				keys = append(keys, att.ThumbnailKey)
+			}
+		}
+
+		for _, key := range keys {
+			if ok, err := dst.Exists(ctx, key); err == nil && ok {
+				skipped++
+				continue
+			}
+
+			if ok, err := src.Exists(ctx, key); err != nil || !ok {
+				fmt.Printf("  missing  %s (achievement %s)\n", key, doc.ID.Hex())
+				missing++
+				continue
+			}
+
+			if !*apply {
+				fmt.Printf("  would copy %s\n", key)
+				copied++
+				continue
+			}
+
+			if err := storage.Copy(ctx, src, dst, key); err != nil {
+				fmt.Printf("  FAILED   %s: %v\n", key, err)
+				failed++
+				continue
+			}
+
+			if *deleteSource {
+				if err := src.Delete(ctx, key); err != nil {
+					fmt.Printf("  copied %s but failed to delete source: %v\n", key, err)
+				}
+			}
+
+			fmt.Printf("  copied   %s\n", key)
+			copied++
+		}
+	}
+
+	fmt.Printf("\n%s -> %s: copied %d, already present %d, missing in source %d, failed %d\n",
+		src.Name(), dst.Name(), copied, skipped, missing, failed)
+
+	if !*apply {
+		fmt.Println("dry run: nothing changed, re-run with -apply to copy files")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d files failed to migrate", failed)
+	}
+	return nil
+}
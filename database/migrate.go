@@ -0,0 +1,230 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations berisi tabel/kolom tambahan di luar skema awal.
+// Semua statement harus idempotent (IF NOT EXISTS) karena dijalankan
+// setiap kali server start.
+var migrations = []string{
+	// anggota tim untuk achievement beregu (ketua / anggota)
+	`CREATE TABLE IF NOT EXISTS achievement_members (
+		id UUID PRIMARY KEY,
+		mongo_achievement_id VARCHAR(24) NOT NULL,
+		student_id UUID NOT NULL,
+		role VARCHAR(20) NOT NULL,
+		confirmed_at TIMESTAMP NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		UNIQUE (mongo_achievement_id, student_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_members_student
+		ON achievement_members (student_id)`,
+
+	// pencabutan achievement yang sudah verified
+	`ALTER TABLE achievement_references
+		ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP NULL,
+		ADD COLUMN IF NOT EXISTS revoked_by UUID NULL,
+		ADD COLUMN IF NOT EXISTS revocation_reason TEXT NULL`,
+	// jika kolom status berupa ENUM, tambahkan nilai 'revoked'
+	`DO $$
+	DECLARE status_type regtype;
+	BEGIN
+		SELECT a.atttypid::regtype INTO status_type
+		FROM pg_attribute a
+		WHERE a.attrelid = 'achievement_references'::regclass AND a.attname = 'status';
+
+		IF EXISTS (SELECT 1 FROM pg_type WHERE oid = status_type AND typtype = 'e') THEN
+			EXECUTE format('ALTER TYPE %s ADD VALUE IF NOT EXISTS %L', status_type, 'revoked');
+		END IF;
+	END $$`,
+
+	// pedoman poin versioned (menggantikan perhitungan hardcoded)
+	`CREATE TABLE IF NOT EXISTS point_rule_sets (
+		id UUID PRIMARY KEY,
+		version INT NOT NULL UNIQUE,
+		name VARCHAR(100) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		is_active BOOLEAN NOT NULL DEFAULT FALSE,
+		created_by UUID NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		activated_at TIMESTAMP NULL
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_point_rule_sets_active
+		ON point_rule_sets (is_active) WHERE is_active`,
+	`CREATE TABLE IF NOT EXISTS point_rules (
+		id UUID PRIMARY KEY,
+		rule_set_id UUID NOT NULL REFERENCES point_rule_sets(id) ON DELETE CASCADE,
+		priority INT NOT NULL,
+		achievement_type VARCHAR(50) NULL,
+		competition_level VARCHAR(50) NULL,
+		rank INT NULL,
+		medal_type VARCHAR(50) NULL,
+		tag VARCHAR(100) NULL,
+		points INT NOT NULL,
+		description TEXT NOT NULL DEFAULT ''
+	)`,
+
+	// job rekalkulasi poin + audit poin lama/baru
+	`CREATE TABLE IF NOT EXISTS point_recalc_jobs (
+		id UUID PRIMARY KEY,
+		scope VARCHAR(20) NOT NULL,
+		scope_value VARCHAR(50) NOT NULL DEFAULT '',
+		mode VARCHAR(10) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		rule_set_version INT NOT NULL DEFAULT 0,
+		total INT NOT NULL DEFAULT 0,
+		changed INT NOT NULL DEFAULT 0,
+		error TEXT NULL,
+		created_by UUID NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		started_at TIMESTAMP NULL,
+		finished_at TIMESTAMP NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS point_recalc_items (
+		id UUID PRIMARY KEY,
+		job_id UUID NOT NULL REFERENCES point_recalc_jobs(id) ON DELETE CASCADE,
+		mongo_achievement_id VARCHAR(24) NOT NULL,
+		student_id UUID NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		old_points INT NOT NULL,
+		new_points INT NOT NULL,
+		old_rule_version INT NOT NULL,
+		new_rule_version INT NOT NULL,
+		applied BOOLEAN NOT NULL DEFAULT FALSE
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_point_recalc_items_job
+		ON point_recalc_items (job_id)`,
+	`CREATE TABLE IF NOT EXISTS point_recalc_student_totals (
+		job_id UUID NOT NULL REFERENCES point_recalc_jobs(id) ON DELETE CASCADE,
+		student_id UUID NOT NULL,
+		old_total INT NOT NULL,
+		new_total INT NOT NULL,
+		PRIMARY KEY (job_id, student_id)
+	)`,
+	// job yang terputus karena server restart
+	`UPDATE point_recalc_jobs
+		SET status = 'failed', error = 'interrupted by server restart', finished_at = NOW()
+		WHERE status IN ('pending', 'running')`,
+
+	// riwayat perubahan attachment (upload, hapus, ganti, urutkan ulang)
+	`CREATE TABLE IF NOT EXISTS achievement_attachment_events (
+		id UUID PRIMARY KEY,
+		mongo_achievement_id VARCHAR(24) NOT NULL,
+		attachment_id VARCHAR(64) NOT NULL DEFAULT '',
+		action VARCHAR(20) NOT NULL,
+		file_name TEXT NOT NULL DEFAULT '',
+		actor_id UUID NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_attachment_events_achievement
+		ON achievement_attachment_events (mongo_achievement_id)`,
+
+	// sertifikat achievement verified, code dipakai di QR / halaman verifikasi publik
+	`CREATE TABLE IF NOT EXISTS achievement_certificates (
+		id UUID PRIMARY KEY,
+		mongo_achievement_id VARCHAR(24) NOT NULL UNIQUE,
+		code VARCHAR(32) NOT NULL UNIQUE,
+		issued_by UUID NULL,
+		issued_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+
+	// nomor SKPI, satu dokumen per mahasiswa (nomor tetap walau dicetak ulang)
+	`CREATE SEQUENCE IF NOT EXISTS skpi_number_seq`,
+	`CREATE TABLE IF NOT EXISTS skpi_documents (
+		id UUID PRIMARY KEY,
+		student_id UUID NOT NULL UNIQUE REFERENCES students(id),
+		number VARCHAR(64) NOT NULL UNIQUE,
+		issued_by UUID NULL,
+		issued_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+
+	// izin laporan global (statistik, leaderboard, perbandingan prodi).
+	// Tidak diberikan ke role mana pun: laporan global memuat nama dan poin
+	// semua mahasiswa, sedangkan dosen wali hanya boleh melihat perwaliannya.
+	// Admin selalu lolos RBAC dan bisa memberikannya lewat role_permissions.
+	`DO $$
+	BEGIN
+		IF to_regclass('permissions') IS NULL THEN
+			RETURN;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'report:view') THEN
+			INSERT INTO permissions (id, name, resource, action, description)
+			VALUES (gen_random_uuid(), 'report:view', 'report', 'view', 'View global achievement reports');
+		END IF;
+	EXCEPTION WHEN undefined_column OR not_null_violation THEN
+		RAISE NOTICE 'report:view permission not seeded: %', SQLERRM;
+	END $$`,
+
+	// laporan terjadwal + arsip hasilnya (file disimpan di storage)
+	`CREATE TABLE IF NOT EXISTS report_schedules (
+		id UUID PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		report_type VARCHAR(30) NOT NULL,
+		filters JSONB NOT NULL DEFAULT '{}',
+		period VARCHAR(20) NOT NULL DEFAULT '',
+		format VARCHAR(10) NOT NULL,
+		cron VARCHAR(100) NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		created_by UUID NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		last_run_at TIMESTAMP NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS report_runs (
+		id UUID PRIMARY KEY,
+		schedule_id UUID NULL REFERENCES report_schedules(id) ON DELETE SET NULL,
+		report_type VARCHAR(30) NOT NULL,
+		filters JSONB NOT NULL DEFAULT '{}',
+		format VARCHAR(10) NOT NULL,
+		trigger VARCHAR(10) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		scheduled_for TIMESTAMP NULL,
+		storage_key TEXT NOT NULL DEFAULT '',
+		file_name TEXT NOT NULL DEFAULT '',
+		content_type VARCHAR(100) NOT NULL DEFAULT '',
+		size BIGINT NOT NULL DEFAULT 0,
+		checksum VARCHAR(64) NOT NULL DEFAULT '',
+		error TEXT NULL,
+		created_by UUID NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		started_at TIMESTAMP NULL,
+		finished_at TIMESTAMP NULL
+	)`,
+	// satu jadwal hanya dijalankan sekali per waktu cron walau ada beberapa instance
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_report_runs_schedule_slot
+		ON report_runs (schedule_id, scheduled_for)`,
+	`CREATE INDEX IF NOT EXISTS idx_report_runs_created
+		ON report_runs (created_at DESC)`,
+	`UPDATE report_runs
+		SET status = 'failed', error = 'interrupted by server restart', finished_at = NOW()
+		WHERE status IN ('pending', 'running')`,
+
+	// izin mengelola jadwal laporan; hanya Admin (selalu lolos RBAC) kecuali
+	// diberikan manual ke role lain
+	`DO $$
+	BEGIN
+		IF to_regclass('permissions') IS NULL THEN
+			RETURN;
+		END IF;
+
+		IF NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'report:schedule') THEN
+			INSERT INTO permissions (id, name, resource, action, description)
+			VALUES (gen_random_uuid(), 'report:schedule', 'report', 'schedule', 'Manage scheduled reports');
+		END IF;
+	EXCEPTION WHEN undefined_column OR not_null_violation THEN
+		RAISE NOTICE 'report:schedule permission not seeded: %', SQLERRM;
+	END $$`,
+}
+
+func MigratePostgres(db *sql.DB) {
+	for i, stmt := range migrations {
+		if _, err := db.Exec(stmt); err != nil {
+			panic(fmt.Sprintf("migration %d failed: %v", i, err))
+		}
+	}
+
+	fmt.Println("PostgreSQL migrations applied")
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoIndexes: index tambahan per collection. CreateMany idempotent
+// selama definisi index tidak berubah.
+var mongoIndexes = map[string][]mongo.IndexModel{
+	"achievements": {
+		// deteksi file bukti yang sama dipakai di achievement lain
+		{Keys: bson.D{{Key: "attachments.sha256", Value: 1}}},
+	},
+}
+
+func MigrateMongo(db *mongo.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for collection, indexes := range mongoIndexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+			panic(fmt.Sprintf("mongo index on %s failed: %v", collection, err))
+		}
+	}
+
+	fmt.Println("MongoDB indexes applied")
+}
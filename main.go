@@ -1,6 +1,10 @@
 package main
 
 import (
+    "log"
+    "os"
+
+    "pbluas/command"
     "pbluas/config"
     "pbluas/database"
 
@@ -9,6 +13,8 @@ import (
 
     "pbluas/middleware"
     "pbluas/route"
+    "pbluas/scanner"
+    "pbluas/storage"
 
     "github.com/gofiber/fiber/v2"
 
@@ -30,15 +36,24 @@ import (
 func main() {
 	config.LoadEnv()
 
-	app := fiber.New()
-
-	// ===== SWAGGER ROUTE =====
-	app.Get("/swagger/*", fiberSwagger.WrapHandler)
-
 	// DB
 	db := database.ConnectPostgres()
+	database.MigratePostgres(db)
 	database.ConnectMongo()
 	mongoDB := database.MongoDB()
+	database.MigrateMongo(mongoDB)
+
+	// File storage (STORAGE_DRIVER=local|s3)
+	fileStorage, err := storage.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Malware scanner (SCANNER_DRIVER=noop|clamav)
+	fileScanner, err := scanner.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 
 	// -------- INIT REPOSITORIES --------
@@ -48,25 +63,78 @@ func main() {
 	lecturerRepo := repository.NewLecturerRepository(db)
 	achievementRepo := repository.NewAchievementRepository(mongoDB)
 	achievementRefRepo := repository.NewAchievementReferenceRepository(db)
+	achievementMemberRepo := repository.NewAchievementMemberRepository(db)
+	pointRuleRepo := repository.NewPointRuleRepository(db)
+	pointRecalcRepo := repository.NewPointRecalcRepository(db)
+	attachmentEventRepo := repository.NewAttachmentEventRepository(db)
+	certificateRepo := repository.NewCertificateRepository(db)
+	skpiRepo := repository.NewSKPIRepository(db)
+	reportScheduleRepo := repository.NewReportScheduleRepository(db)
 
 	// -------- INIT SERVICES --------
 	userService := service.NewUserService(userRepo, permRepo)
 	studentService := service.NewStudentService(studentRepo, lecturerRepo,  achievementRepo, achievementRefRepo )
-	lecturerService := service.NewLecturerService(lecturerRepo, studentRepo)
-	achievementService := service.NewAchievementService(achievementRepo,achievementRefRepo,studentRepo, )
-	reportService := service.NewReportService(studentRepo, achievementRefRepo, achievementRepo)
+	lecturerService := service.NewLecturerService(lecturerRepo, studentRepo, achievementRefRepo, achievementRepo)
+	pointRuleService := service.NewPointRuleService(pointRuleRepo, achievementRepo)
+	pointRecalcService := service.NewPointRecalculationService(pointRecalcRepo, pointRuleService, achievementRepo, achievementRefRepo, achievementMemberRepo)
+	achievementService := service.NewAchievementService(achievementRepo,achievementRefRepo,studentRepo, achievementMemberRepo, pointRuleService, fileStorage, attachmentEventRepo, fileScanner)
+	certificateService := service.NewCertificateService(certificateRepo, achievementService, userRepo)
+	reportService := service.NewReportService(studentRepo, achievementRefRepo, achievementRepo, achievementMemberRepo)
+	skpiService := service.NewSKPIService(skpiRepo, studentRepo, achievementRefRepo, achievementRepo, achievementMemberRepo)
+	reportScheduleService := service.NewReportScheduleService(reportScheduleRepo, reportService, fileStorage)
+	consistencyService := service.NewConsistencyService(achievementRepo, achievementRefRepo, studentRepo)
+
+	// -------- CLI SUBCOMMANDS --------
+	// contoh: go run . consistency repair -apply
+	if len(os.Args) > 1 {
+		err := command.Run(os.Args[1:], command.Services{
+			Consistency:     consistencyService,
+			AchievementRepo: achievementRepo,
+			Achievement:     achievementService,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// attachment lama / scan yang gagal: scan ulang di background
+	achievementService.ScanPendingAsync()
+
+	// laporan terjadwal (cron), hanya saat server berjalan
+	if err := reportScheduleService.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	// BodyLimit default Fiber 4MB, dinaikkan mengikuti ATTACHMENT_MAX_FILE_MB
+	app := fiber.New(fiber.Config{
+		BodyLimit: max(fiber.DefaultBodyLimit, service.MaxUploadBodySize()),
+	})
+
+	// ===== SWAGGER ROUTE =====
+	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
 	// -------- PUBLIC ROUTES --------
 	auth := app.Group("/api/v1/auth")
 	route.AuthRoute(auth, userService)
 
+	// signed link (laporan / email), diverifikasi lewat HMAC bukan JWT
+	files := app.Group("/api/v1/files")
+	route.FileRoute(files, achievementService)
+
+	// verifikasi sertifikat (QR code), tanpa login
+	route.VerifyRoute(app, certificateService)
+
 	// -------- PROTECTED ROUTES --------
 	api := app.Group("/api/v1")
 	api.Use(middleware.JWTMiddleware)
+	route.LecturerRoute(api, permRepo, lecturerService)
 	route.AdminRoute(api, permRepo, userService, studentService, lecturerService)
 	route.MahasiswaRoute(api, studentService)
 	route.AchievementRoute(api, achievementService)
-	route.ReportRoutes(api, reportService)
+	route.CertificateRoute(api, certificateService)
+	route.ReportRoutes(api, permRepo, reportService, skpiService, reportScheduleService)
+	route.PointRuleRoute(api, permRepo, pointRuleService, pointRecalcService)
 
 
 	app.Listen(":8080")
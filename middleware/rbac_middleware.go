@@ -5,10 +5,14 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"pbluas/app/repository"
 )
 
-func RBACMiddleware(c *fiber.Ctx, permRepo *repository.PermissionRepository, requiredPerms ...string) error {
+// PermissionLoader: sumber permission per role (*repository.PermissionRepository)
+type PermissionLoader interface {
+	GetPermissionsByRole(roleName string) ([]string, error)
+}
+
+func RBACMiddleware(c *fiber.Ctx, permRepo PermissionLoader, requiredPerms ...string) error {
 	// Ambil token dari header Authorization
 	auth := c.Get("Authorization")
 	if auth == "" {
@@ -47,12 +47,22 @@ func AchievementRoute(api fiber.Router, achievementService *service.AchievementS
 	})
 
 	ach.Get("/", achievementService.ListByRole)
+	ach.Get("/types", achievementService.ListTypes)
 	ach.Get("/:id", achievementService.Detail)
 	ach.Put("/:id", achievementService.Update)
+	ach.Get("/:id/attachments", achievementService.ListAttachments)
 	ach.Post("/:id/attachments", achievementService.UploadAttachment)
+	ach.Put("/:id/attachments/order", achievementService.ReorderAttachments) // sebelum /:attachmentId
+	ach.Get("/:id/attachments/:attachmentId", achievementService.DownloadAttachment)
+	ach.Put("/:id/attachments/:attachmentId", achievementService.ReplaceAttachment)
+	ach.Delete("/:id/attachments/:attachmentId", achievementService.DeleteAttachment)
+	ach.Get("/:id/attachments/:attachmentId/link", achievementService.AttachmentLink)
+	ach.Get("/:id/attachments/:attachmentId/preview", achievementService.AttachmentPreview)
 	ach.Delete("/:id", achievementService.Delete)
+	ach.Post("/:id/members/confirm", achievementService.ConfirmMembership)
 	ach.Post("/:id/submit", achievementService.Submit)
 	ach.Post("/:id/verify", achievementService.Verify)
 	ach.Post("/:id/reject", achievementService.Reject)
+	ach.Post("/:id/revoke", achievementService.Revoke)
 	ach.Get("/:id/history", achievementService.History)
 }	
@@ -0,0 +1,17 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"pbluas/app/service"
+)
+
+// CertificateRoute: download sertifikat (butuh JWT)
+func CertificateRoute(api fiber.Router, certificateService *service.CertificateService) {
+	api.Get("/achievements/:id/certificate", certificateService.Download)
+}
+
+// VerifyRoute: halaman verifikasi publik yang dituju QR code sertifikat
+func VerifyRoute(app fiber.Router, certificateService *service.CertificateService) {
+	app.Get("/verify/:code", certificateService.Verify)
+}
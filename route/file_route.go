@@ -0,0 +1,13 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"pbluas/app/service"
+)
+
+// FileRoute: download publik lewat signed link (tanpa JWT).
+// Akses ditentukan oleh parameter expires & signature.
+func FileRoute(router fiber.Router, achievementService *service.AchievementService) {
+	router.Get("/attachments/:id/:attachmentId", achievementService.SignedAttachmentDownload)
+}
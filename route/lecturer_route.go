@@ -0,0 +1,21 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"pbluas/app/repository"
+	"pbluas/app/service"
+	"pbluas/middleware"
+)
+
+func LecturerRoute(api fiber.Router, permRepo *repository.PermissionRepository, lecturerService *service.LecturerService) {
+
+	require := func(perms ...string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			return middleware.RBACMiddleware(c, permRepo, perms...)
+		}
+	}
+
+	// /me harus didaftarkan sebelum /:id
+	api.Get("/lecturers/me/dashboard", lecturerService.MyDashboard)
+	api.Get("/lecturers/:id/dashboard", require("user:manage"), lecturerService.LecturerDashboard)
+}
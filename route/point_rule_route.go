@@ -0,0 +1,35 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"pbluas/app/repository"
+	"pbluas/app/service"
+	"pbluas/middleware"
+)
+
+func PointRuleRoute(api fiber.Router, permRepo *repository.PermissionRepository, pointRuleService *service.PointRuleService,
+	recalcService *service.PointRecalculationService) {
+
+	require := func(perms ...string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			return middleware.RBACMiddleware(c, permRepo, perms...)
+		}
+	}
+
+	rules := api.Group("/point-rules", require("point_rule:manage"))
+
+	rules.Get("/", pointRuleService.List)
+	rules.Get("/active", pointRuleService.GetActive)
+	rules.Post("/", pointRuleService.Create)
+	rules.Post("/dry-run", pointRuleService.DryRun)
+
+	// harus didaftarkan sebelum /:id
+	rules.Post("/recalculations", recalcService.Start)
+	rules.Get("/recalculations", recalcService.List)
+	rules.Get("/recalculations/:id", recalcService.Get)
+	rules.Post("/recalculations/:id/apply", recalcService.Apply)
+
+	rules.Get("/:id", pointRuleService.GetByID)
+	rules.Post("/:id/activate", pointRuleService.Activate)
+	rules.Delete("/:id", pointRuleService.Delete)
+}
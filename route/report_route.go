@@ -9,13 +9,44 @@ import (
 
 func ReportRoutes(
 	api fiber.Router,
+	permRepo middleware.PermissionLoader,
 	reportService *service.ReportService,
+	skpiService *service.SKPIService,
+	scheduleService *service.ReportScheduleService,
 ) {
+	require := func(perms ...string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			return middleware.RBACMiddleware(c, permRepo, perms...)
+		}
+	}
+
 	report := api.Group(
 		"/reports",
 		middleware.JWTMiddleware, // ✅ LANGSUNG
 	)
 
+	// laporan per mahasiswa: aturan akses dicek di handler
+	// (mahasiswa sendiri, dosen wali perwalian, admin semua)
 	report.Get("/student/:id", reportService.GetStudentReport)
-	report.Get("/statistics", reportService.GetStatistics)
+	report.Get("/student/:id/skpi", skpiService.Download)
+
+	// laporan global
+	report.Get("/statistics", require(service.PermissionReportView), reportService.GetStatistics)
+	report.Get("/leaderboard", require(service.PermissionReportView), reportService.GetLeaderboard)
+	report.Get("/programs/comparison", require(service.PermissionReportView), reportService.GetProgramComparison)
+	report.Get("/accreditation", require(service.PermissionReportView), reportService.GetAccreditation)
+	report.Post("/skpi/batch", skpiService.Batch)
+
+	// laporan terjadwal + arsip hasilnya
+	schedules := report.Group("/schedules", require(service.PermissionReportSchedule))
+	schedules.Post("/", scheduleService.CreateSchedule)
+	schedules.Get("/", scheduleService.ListSchedules)
+	schedules.Get("/:id", scheduleService.GetSchedule)
+	schedules.Put("/:id", scheduleService.UpdateSchedule)
+	schedules.Delete("/:id", scheduleService.DeleteSchedule)
+	schedules.Post("/:id/run", scheduleService.RunSchedule)
+
+	report.Get("/archive", require(service.PermissionReportView), scheduleService.ListArchive)
+	report.Get("/archive/:id", require(service.PermissionReportView), scheduleService.GetArchive)
+	report.Get("/archive/:id/download", require(service.PermissionReportView), scheduleService.DownloadArchive)
 }
@@ -0,0 +1,206 @@
+package route
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pbluas/app/models"
+	"pbluas/app/repository"
+	"pbluas/app/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakePermissions: permission per role tanpa database
+type fakePermissions map[string][]string
+
+func (f fakePermissions) GetPermissionsByRole(roleName string) ([]string, error) {
+	return f[roleName], nil
+}
+
+// report:view tidak di-seed ke role mana pun; "Pimpinan" mewakili role
+// yang diberi izin itu oleh admin
+var testPermissions = fakePermissions{
+	"Mahasiswa":  {"achievement:read"},
+	"Dosen Wali": {"achievement:read", "achievement:verify"},
+	"Pimpinan":   {service.PermissionReportView},
+}
+
+// fakeStudents: hanya GetStudentByID yang dipakai GetStudentReport
+type fakeStudents struct {
+	repository.StudentRepository
+	students map[string]models.StudentDetail
+}
+
+func (f fakeStudents) GetStudentByID(id string) (*models.StudentDetail, error) {
+	st, ok := f.students[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &st, nil
+}
+
+// fakeDB: database/sql palsu untuk AchievementReferenceRepository.
+// Hanya menjawab cek dosen wali (advisees: user_id dosen -> students.id);
+// query lain mengembalikan nol baris.
+type fakeDB struct {
+	advisees map[string][]string
+}
+
+func (f fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn(f), nil }
+func (f fakeDB) Driver() driver.Driver                        { return f }
+func (f fakeDB) Open(string) (driver.Conn, error)             { return fakeConn(f), nil }
+
+type fakeConn fakeDB
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "JOIN lecturers") {
+		return &fakeRows{columns: 5}, nil
+	}
+
+	// IsAdvisorOfStudent: $1 = students.id, $2 = user_id dosen
+	count := int64(0)
+	for _, id := range c.advisees[args[1].Value.(string)] {
+		if id == args[0].Value.(string) {
+			count = 1
+		}
+	}
+	return &fakeRows{columns: 1, values: [][]driver.Value{{count}}}, nil
+}
+
+type fakeRows struct {
+	columns int
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, r.columns) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func bearer(t *testing.T, role, userID string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":   userID,
+		"role": role,
+	})
+	signed, err := token.SignedString([]byte("secret123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + signed
+}
+
+func newReportTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "secret123")
+
+	db := sql.OpenDB(fakeDB{advisees: map[string][]string{
+		"user-dosen-1": {"student-1"},
+		"user-dosen-2": {"student-2"},
+	}})
+	t.Cleanup(func() { db.Close() })
+
+	reportService := &service.ReportService{
+		StudentRepo: fakeStudents{students: map[string]models.StudentDetail{
+			"student-1": {ID: "student-1", UserID: "user-mhs-1", StudentID: "434231001"},
+		}},
+		RefRepo:    &repository.AchievementReferenceRepository{DB: db},
+		MemberRepo: &repository.AchievementMemberRepository{DB: db},
+	}
+
+	app := fiber.New()
+	ReportRoutes(
+		app.Group("/api/v1"),
+		testPermissions,
+		reportService,
+		&service.SKPIService{},
+		&service.ReportScheduleService{},
+	)
+	return app
+}
+
+func TestReportStatisticsRequiresReportView(t *testing.T) {
+	app := newReportTestApp(t)
+
+	tests := []struct {
+		role string
+		want int
+	}{
+		{"Mahasiswa", fiber.StatusForbidden},
+		{"Dosen Wali", fiber.StatusForbidden},
+		{"Tamu", fiber.StatusForbidden},
+		// lolos RBAC; level tidak valid ditolak handler sebelum query apa pun
+		{"Pimpinan", fiber.StatusUnprocessableEntity},
+		{"Admin", fiber.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/reports/statistics?level=galactic", nil)
+			req.Header.Set("Authorization", bearer(t, tt.role, "user-1"))
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("role %s: status = %d, want %d", tt.role, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestStudentReportAccess(t *testing.T) {
+	app := newReportTestApp(t)
+
+	tests := []struct {
+		name      string
+		role      string
+		userID    string
+		studentID string
+		want      int
+	}{
+		{"mahasiswa sendiri", "Mahasiswa", "user-mhs-1", "student-1", fiber.StatusOK},
+		{"mahasiswa lain", "Mahasiswa", "user-mhs-2", "student-1", fiber.StatusForbidden},
+		{"dosen wali perwalian", "Dosen Wali", "user-dosen-1", "student-1", fiber.StatusOK},
+		{"dosen wali bukan perwalian", "Dosen Wali", "user-dosen-2", "student-1", fiber.StatusForbidden},
+		{"admin", "Admin", "user-admin", "student-1", fiber.StatusOK},
+		{"role tidak dikenal", "Tamu", "user-mhs-1", "student-1", fiber.StatusForbidden},
+		{"mahasiswa tidak ada", "Admin", "user-admin", "student-9", fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/reports/student/"+tt.studentID, nil)
+			req.Header.Set("Authorization", bearer(t, tt.role, tt.userID))
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ukuran chunk INSTREAM, harus di bawah StreamMaxLength clamd
+const clamChunkSize = 64 * 1024
+
+// ClamAVScanner mengirim file ke clamd dengan perintah INSTREAM:
+//
+//	zINSTREAM\0 <len uint32 big-endian><data> ... <0 uint32>
+//	balasan: "stream: OK" atau "stream: <signature> FOUND"
+type ClamAVScanner struct {
+	Address string // host:port clamd
+	Timeout time.Duration
+}
+
+func (s *ClamAVScanner) Name() string { return DriverClamAV }
+
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+
+	if s.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	buf := make([]byte, clamChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, fmt.Errorf("clamd: %w", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, fmt.Errorf("clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	// chunk kosong = akhir stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	return parseClamReply(reply)
+}
+
+func parseClamReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return &Result{Clean: true}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Clean: false, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	default:
+		// contoh: "INSTREAM size limit exceeded. ERROR"
+		return nil, fmt.Errorf("clamd: %s", reply)
+	}
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result adalah hasil scan satu file
+type Result struct {
+	Clean     bool
+	Signature string // nama malware jika tidak clean
+}
+
+// Scanner memeriksa isi file terhadap malware
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// Driver yang didukung (env SCANNER_DRIVER)
+const (
+	DriverNoop   = "noop"
+	DriverClamAV = "clamav"
+)
+
+// FromEnv membuat scanner sesuai SCANNER_DRIVER (default noop):
+//
+//	clamav: CLAMAV_ADDRESS (default localhost:3310), CLAMAV_TIMEOUT_SECONDS (default 60)
+func FromEnv() (Scanner, error) {
+	switch strings.ToLower(os.Getenv("SCANNER_DRIVER")) {
+	case "", DriverNoop:
+		return NoopScanner{}, nil
+
+	case DriverClamAV:
+		addr := os.Getenv("CLAMAV_ADDRESS")
+		if addr == "" {
+			addr = "localhost:3310"
+		}
+
+		timeout := 60 * time.Second
+		if seconds, err := strconv.Atoi(os.Getenv("CLAMAV_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		}
+
+		return &ClamAVScanner{Address: addr, Timeout: timeout}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown scanner driver %q", os.Getenv("SCANNER_DRIVER"))
+	}
+}
+
+// NoopScanner menganggap semua file clean (untuk development / tanpa clamd)
+type NoopScanner struct{}
+
+func (NoopScanner) Name() string { return DriverNoop }
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	return &Result{Clean: true}, nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound: object dengan key tersebut tidak ada
+	ErrNotFound = errors.New("storage: object not found")
+
+	// ErrPresignUnsupported: backend tidak bisa membuat URL langsung
+	// (misalnya local disk), file harus dialirkan lewat API
+	ErrPresignUnsupported = errors.New("storage: presigned url not supported")
+)
+
+// ObjectInfo adalah metadata object yang dikembalikan Get
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Storage adalah backend penyimpanan file attachment.
+// Key selalu memakai "/" sebagai pemisah, misalnya "achievements/<id>_file.pdf".
+type Storage interface {
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Driver yang didukung (env STORAGE_DRIVER)
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// FromEnv membuat backend sesuai STORAGE_DRIVER (default local)
+func FromEnv() (Storage, error) {
+	return New(os.Getenv("STORAGE_DRIVER"))
+}
+
+// New membuat backend berdasarkan nama driver; konfigurasi tiap driver
+// diambil dari environment:
+//
+//	local: STORAGE_LOCAL_ROOT (default ./uploads)
+//	s3:    S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY, S3_BUCKET,
+//	       S3_REGION, S3_USE_SSL (true/false)
+func New(driver string) (Storage, error) {
+	switch strings.ToLower(driver) {
+	case "", DriverLocal:
+		root := os.Getenv("STORAGE_LOCAL_ROOT")
+		if root == "" {
+			root = "./uploads"
+		}
+		return NewLocalStorage(root)
+
+	case DriverS3:
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+// Copy menyalin satu object dari src ke dst (dipakai migrasi backend)
+func Copy(ctx context.Context, src, dst Storage, key string) error {
+	r, info, err := src.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return dst.Put(ctx, key, r, info.Size, info.ContentType)
+}
+
+// cleanKey menolak key kosong atau yang keluar dari root ("..")
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(strings.ReplaceAll(key, "\\", "/"), "/")
+	if key == "" {
+		return "", errors.New("storage: empty key")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return key, nil
+}
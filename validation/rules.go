@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/robfig/cron/v3"
+
+	"pbluas/app/models"
+)
+
+// registerRules mendaftarkan rule yang tidak bisa ditulis sebagai tag biasa
+func registerRules(v *validator.Validate) {
+	_ = v.RegisterValidation("achievement_type", func(fl validator.FieldLevel) bool {
+		return models.GetAchievementTypeSpec(fl.Field().String()) != nil
+	})
+
+	v.RegisterStructValidation(achievementRequestRules, models.AchievementCreateRequest{})
+	v.RegisterStructValidation(recalcRequestRules, models.PointRecalcRequest{})
+	v.RegisterStructValidation(skpiBatchRules, models.SKPIBatchRequest{})
+	v.RegisterStructValidation(statisticsFilterRules, models.StatisticsFilter{})
+
+	// cron 5 field (menit jam tanggal bulan hari), sama dengan scheduler laporan
+	_ = v.RegisterValidation("cron", func(fl validator.FieldLevel) bool {
+		_, err := cron.ParseStandard(fl.Field().String())
+		return err == nil
+	})
+	v.RegisterStructValidation(reportScheduleRules, models.ReportScheduleRequest{})
+}
+
+var referenceStatuses = map[string]bool{
+	"draft": true, "submitted": true, "verified": true, "rejected": true, "revoked": true,
+}
+
+// achievementTypeParam dipakai sebagai Param error achievement_type
+func achievementTypeParam() string {
+	var types []string
+	for _, t := range models.AchievementTypes {
+		types = append(types, t.Type)
+	}
+	return strings.Join(types, " ")
+}
+
+// achievementRequestRules: field details wajib per jenis achievement
+// dan urutan tanggal periode
+func achievementRequestRules(sl validator.StructLevel) {
+	req := sl.Current().Interface().(models.AchievementCreateRequest)
+
+	spec := models.GetAchievementTypeSpec(req.AchievementType)
+	if spec == nil {
+		// jenis tidak dikenal sudah dilaporkan oleh tag achievement_type
+		return
+	}
+
+	for _, field := range spec.MissingFields(req.Details) {
+		sl.ReportError(nil, "details."+field, field, "required", "")
+	}
+
+	d := req.Details
+	if d.PeriodStart != "" && d.PeriodEnd != "" && d.PeriodEnd < d.PeriodStart {
+		sl.ReportError(d.PeriodEnd, "details.periodEnd", "PeriodEnd", "gtefield", "periodStart")
+	}
+}
+
+func recalcRequestRules(sl validator.StructLevel) {
+	req := sl.Current().Interface().(models.PointRecalcRequest)
+
+	switch req.Scope {
+	case models.RecalcScopeType:
+		if req.ScopeValue == "" {
+			sl.ReportError(req.ScopeValue, "scope_value", "ScopeValue", "required", "")
+		} else if models.GetAchievementTypeSpec(req.ScopeValue) == nil {
+			sl.ReportError(req.ScopeValue, "scope_value", "ScopeValue", "achievement_type", achievementTypeParam())
+		}
+	case models.RecalcScopeStatus:
+		if req.ScopeValue == "" {
+			sl.ReportError(req.ScopeValue, "scope_value", "ScopeValue", "required", "")
+		} else if !referenceStatuses[req.ScopeValue] {
+			sl.ReportError(req.ScopeValue, "scope_value", "ScopeValue", "oneof", "draft submitted verified rejected revoked")
+		}
+	}
+}
+
+// skpiBatchRules: angkatan atau daftar mahasiswa harus diisi
+func skpiBatchRules(sl validator.StructLevel) {
+	req := sl.Current().Interface().(models.SKPIBatchRequest)
+
+	if strings.TrimSpace(req.AcademicYear) == "" && len(req.StudentIDs) == 0 {
+		sl.ReportError(req.AcademicYear, "academicYear", "AcademicYear", "required_without", "studentIds")
+	}
+}
+
+// statisticsFilterRules: akhir periode tidak boleh sebelum awal periode
+func statisticsFilterRules(sl validator.StructLevel) {
+	f := sl.Current().Interface().(models.StatisticsFilter)
+
+	if f.From != "" && f.To != "" && f.To < f.From {
+		sl.ReportError(f.To, "to", "To", "gtefield", "from")
+	}
+}
+
+// reportScheduleRules: periode relatif tidak boleh digabung dengan
+// filters.from / filters.to, dan laporan akreditasi tidak punya versi CSV
+func reportScheduleRules(sl validator.StructLevel) {
+	req := sl.Current().Interface().(models.ReportScheduleRequest)
+
+	if req.Period != "" && (req.Filters.From != "" || req.Filters.To != "") {
+		sl.ReportError(req.Period, "period", "Period", "excluded_with", "filters.from filters.to")
+	}
+	if req.ReportType == models.ReportTypeAccreditation && req.Format == "csv" {
+		sl.ReportError(req.Format, "format", "Format", "oneof", "json xlsx")
+	}
+}
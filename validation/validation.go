@@ -0,0 +1,165 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// FieldError adalah satu field yang tidak valid.
+// Code bersifat machine-readable dan stabil (dipakai client untuk i18n).
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// pakai nama field JSON supaya error sama dengan payload client
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	registerRules(v)
+	return v
+}
+
+// Struct memvalidasi struct berdasarkan tag `validate` dan rule struct-level
+// yang didaftarkan di rules.go. Mengembalikan nil jika valid.
+func Struct(s interface{}) []FieldError {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []FieldError{{Field: "", Code: "invalid", Message: err.Error()}}
+	}
+
+	var out []FieldError
+	for _, fe := range verrs {
+		out = append(out, toFieldError(fe))
+	}
+	return out
+}
+
+// Respond menulis response 422 yang seragam untuk semua endpoint.
+// Pemakaian di handler:
+//
+//	if errs := validation.Struct(&req); errs != nil {
+//		return validation.Respond(c, errs)
+//	}
+func Respond(c *fiber.Ctx, errs []FieldError) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"code":    422,
+		"message": "Unprocessable Entity",
+		"errors":  errs,
+	})
+}
+
+func toFieldError(fe validator.FieldError) FieldError {
+	// Namespace: "AchievementCreateRequest.details.eventDate" -> "details.eventDate"
+	field := fe.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	param := fe.Param()
+	switch fe.Tag() {
+	case "achievement_type":
+		if param == "" {
+			param = achievementTypeParam()
+		}
+	case "required_without":
+		// param berisi nama field Go, samakan dengan nama JSON
+		param = lowerFirst(param)
+	}
+
+	code, message := describe(fe.Tag(), fe.Kind(), param)
+	return FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+		Param:   param,
+	}
+}
+
+func describe(tag string, kind reflect.Kind, param string) (string, string) {
+	switch tag {
+	case "required", "notblank":
+		return "required", "field is required"
+	case "email":
+		return "invalid_email", "must be a valid email address"
+	case "uuid", "uuid4":
+		return "invalid_id", "must be a valid UUID"
+	case "mongodb":
+		return "invalid_id", "must be a valid ObjectID"
+	case "oneof", "achievement_type":
+		return "invalid_choice", "must be one of: " + param
+	case "datetime":
+		return "invalid_date", "must be a date in YYYY-MM-DD format"
+	case "min":
+		if kind == reflect.String {
+			return "too_short", "must be at least " + param + " characters"
+		}
+		if kind == reflect.Slice {
+			return "too_few", "must contain at least " + param + " items"
+		}
+		return "too_small", "must be at least " + param
+	case "max":
+		if kind == reflect.String {
+			return "too_long", "must be at most " + param + " characters"
+		}
+		if kind == reflect.Slice {
+			return "too_many", "must contain at most " + param + " items"
+		}
+		return "too_large", "must be at most " + param
+	case "gt", "gte":
+		return "too_small", "must be greater than " + orEqual(tag) + param
+	case "lt", "lte":
+		return "too_large", "must be less than " + orEqual(tag) + param
+	case "gtefield":
+		return "before_start", "must not be before " + param
+	case "required_without":
+		return "required", "required when " + param + " is empty"
+	case "excluded_with":
+		return "conflict", "must be empty when " + param + " is set"
+	case "cron":
+		return "invalid_cron", "must be a cron expression (minute hour day month weekday)"
+	default:
+		return tag, "failed on rule " + tag
+	}
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func orEqual(tag string) string {
+	if strings.HasSuffix(tag, "e") {
+		return "or equal to "
+	}
+	return ""
+}